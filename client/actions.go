@@ -0,0 +1,240 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// ErrInvalidWorkflowInputs is returned when github rejects the inputs of workflow
+// dispatch, for example the unknown or missing required inputs, or the workflow
+// doesn't have the workflow_dispatch trigger.
+var ErrInvalidWorkflowInputs = errors.New("invalid workflow inputs")
+
+// CreateOrUpdateRepoSecret sets the Actions secret of repo. The plaintext is
+// encrypted by the public key of repo with the sealed box of libsodium, which
+// is required by github.
+func (cl client) CreateOrUpdateRepoSecret(org, repo, name, plaintext string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	key, _, err := cl.c.Actions.GetRepoPublicKey(ctx, org, repo)
+	if err != nil {
+		return fmt.Errorf("failed to get public key of %s/%s: %w", org, repo, err)
+	}
+
+	encrypted, err := encryptSecret(key.GetKey(), plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s of %s/%s: %w", name, org, repo, err)
+	}
+
+	_, err = cl.c.Actions.CreateOrUpdateRepoSecret(ctx, org, repo, &sdk.EncryptedSecret{
+		Name:           name,
+		KeyID:          key.GetKeyID(),
+		EncryptedValue: encrypted,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set secret %s of %s/%s: %w", name, org, repo, err)
+	}
+
+	return nil
+}
+
+// ListRepoSecrets returns all the Actions secrets of repo without the values.
+func (cl client) ListRepoSecrets(org, repo string) ([]*sdk.Secret, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var secrets []*sdk.Secret
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Actions.ListRepoSecrets(ctx, org, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets of %s/%s: %w", org, repo, err)
+		}
+
+		secrets = append(secrets, v.Secrets...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return secrets, nil
+}
+
+// encryptSecret encrypts the plaintext with the sealed box by the base64 encoded
+// public key, and returns the base64 encoded ciphertext.
+func encryptSecret(publicKey, plaintext string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %v", err)
+	}
+
+	if len(b) != 32 {
+		return "", fmt.Errorf("invalid public key: the size is %d rather than 32", len(b))
+	}
+
+	var key [32]byte
+	copy(key[:], b)
+
+	out, err := box.SealAnonymous(nil, []byte(plaintext), &key, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// ActionsVariable is the Actions variable of repo, which is plaintext.
+type ActionsVariable struct {
+	Name      string    `json:"name"`
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// actionsVariableRequest is the request of creating or updating the variable,
+// which has only the writable fields.
+type actionsVariableRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CreateOrUpdateRepoVariable sets the Actions variable of repo. The sdk doesn't
+// support the variables yet.
+func (cl client) CreateOrUpdateRepoVariable(org, repo, name, value string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v := &actionsVariableRequest{Name: name, Value: value}
+
+	req, err := cl.c.NewRequest("PATCH", fmt.Sprintf("repos/%s/%s/actions/variables/%s", org, repo, name), v)
+	if err != nil {
+		return err
+	}
+
+	r, err := cl.c.Do(ctx, req, nil)
+	if err == nil {
+		return nil
+	}
+
+	if r == nil || r.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to update variable %s of %s/%s: %w", name, org, repo, err)
+	}
+
+	// The variable doesn't exist.
+	req, err = cl.c.NewRequest("POST", fmt.Sprintf("repos/%s/%s/actions/variables", org, repo), v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cl.c.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("failed to create variable %s of %s/%s: %w", name, org, repo, err)
+	}
+
+	return nil
+}
+
+// ListRepoVariables returns all the Actions variables of repo.
+func (cl client) ListRepoVariables(org, repo string) ([]ActionsVariable, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var variables []ActionsVariable
+
+	for page := 1; ; {
+		u := fmt.Sprintf("repos/%s/%s/actions/variables?page=%d&per_page=30", org, repo, page)
+		req, err := cl.c.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var v struct {
+			Variables []ActionsVariable `json:"variables"`
+		}
+
+		resp, err := cl.c.Do(ctx, req, &v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list variables of %s/%s: %w", org, repo, err)
+		}
+
+		variables = append(variables, v.Variables...)
+
+		if page, err = nextPage(resp); err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+	}
+
+	return variables, nil
+}
+
+// CreateRepositoryDispatch triggers the workflows of repo listening to the
+// repository_dispatch event of the event type. The payload is passed to the
+// workflows as github.event.client_payload, and it can be nil.
+func (cl client) CreateRepositoryDispatch(org, repo, eventType string, payload json.RawMessage) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	opt := sdk.DispatchRequestOptions{EventType: eventType}
+	if len(payload) > 0 {
+		opt.ClientPayload = &payload
+	}
+
+	if _, _, err := cl.c.Repositories.Dispatch(ctx, org, repo, opt); err != nil {
+		return fmt.Errorf("failed to dispatch %s to %s/%s: %w", eventType, org, repo, err)
+	}
+
+	return nil
+}
+
+// CreateWorkflowDispatch triggers the workflow on the ref which is a branch or tag.
+// The workflow is the file name such as "ci.yml", or the id of workflow. The inputs
+// are validated by github, and ErrInvalidWorkflowInputs is returned if it rejects them.
+func (cl client) CreateWorkflowDispatch(org, repo, workflowFileName, ref string, inputs map[string]interface{}) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	event := sdk.CreateWorkflowDispatchEventRequest{Ref: ref, Inputs: inputs}
+
+	var resp *sdk.Response
+	var err error
+	if id, err1 := strconv.ParseInt(workflowFileName, 10, 64); err1 == nil {
+		resp, err = cl.c.Actions.CreateWorkflowDispatchEventByID(ctx, org, repo, id, event)
+	} else {
+		resp, err = cl.c.Actions.CreateWorkflowDispatchEventByFileName(ctx, org, repo, workflowFileName, event)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+		return fmt.Errorf(
+			"failed to dispatch workflow %s of %s/%s on %s: %w: %v",
+			workflowFileName, org, repo, ref, ErrInvalidWorkflowInputs, err,
+		)
+	}
+
+	return fmt.Errorf("failed to dispatch workflow %s of %s/%s on %s: %w", workflowFileName, org, repo, ref, err)
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// testKeyPair returns the key pair generated from a fixed seed, so that the
+// public key is known.
+func testKeyPair(t *testing.T) (*[32]byte, *[32]byte) {
+	pub, priv, err := box.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pub, priv
+}
+
+func TestEncryptSecret(t *testing.T) {
+	pub, priv := testKeyPair(t)
+
+	cases := []struct {
+		name      string
+		publicKey string
+		plaintext string
+		wantErr   bool
+	}{
+		{name: "known key", publicKey: base64.StdEncoding.EncodeToString(pub[:]), plaintext: "s3cret"},
+		{name: "empty plaintext", publicKey: base64.StdEncoding.EncodeToString(pub[:]), plaintext: ""},
+		{name: "not base64", publicKey: "not base64!", wantErr: true},
+		{name: "wrong size", publicKey: base64.StdEncoding.EncodeToString(pub[:16]), wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := encryptSecret(c.publicKey, c.plaintext)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sealed, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				t.Fatalf("the ciphertext is not base64: %v", err)
+			}
+
+			opened, ok := box.OpenAnonymous(nil, sealed, pub, priv)
+			if !ok {
+				t.Fatal("failed to open the sealed box")
+			}
+
+			if string(opened) != c.plaintext {
+				t.Errorf("got plaintext %q, want %q", opened, c.plaintext)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateRepoSecret(t *testing.T) {
+	pub, priv := testKeyPair(t)
+
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"key_id":"1","key":%q}`, base64.StdEncoding.EncodeToString(pub[:]))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := s.client(t).CreateOrUpdateRepoSecret("owner", "repo", "TOKEN", "s3cret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"GET /repos/owner/repo/actions/secrets/public-key",
+		"PUT /repos/owner/repo/actions/secrets/TOKEN",
+	}
+	if got := s.received(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got requests %v, want %v", got, want)
+	}
+
+	var secret struct {
+		KeyID          string `json:"key_id"`
+		EncryptedValue string `json:"encrypted_value"`
+	}
+	if err := json.Unmarshal([]byte(s.bodies[1]), &secret); err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.KeyID != "1" {
+		t.Errorf("got key id %q, want 1", secret.KeyID)
+	}
+
+	sealed, _ := base64.StdEncoding.DecodeString(secret.EncryptedValue)
+	if opened, ok := box.OpenAnonymous(nil, sealed, pub, priv); !ok || string(opened) != "s3cret" {
+		t.Errorf("got plaintext %q, opened %t", opened, ok)
+	}
+}
+
+func TestCreateOrUpdateRepoVariable(t *testing.T) {
+	cases := []struct {
+		name         string
+		patchStatus  int
+		wantRequests []string
+	}{
+		{
+			name:         "update",
+			patchStatus:  http.StatusNoContent,
+			wantRequests: []string{"PATCH /repos/owner/repo/actions/variables/NAME"},
+		},
+		{
+			name:        "create",
+			patchStatus: http.StatusNotFound,
+			wantRequests: []string{
+				"PATCH /repos/owner/repo/actions/variables/NAME",
+				"POST /repos/owner/repo/actions/variables",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					w.WriteHeader(c.patchStatus)
+
+					return
+				}
+
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			if err := s.client(t).CreateOrUpdateRepoVariable("owner", "repo", "NAME", "v"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Errorf("got requests %v, want %v", got, c.wantRequests)
+			}
+
+			// Only the writable fields are sent.
+			for _, b := range s.bodies {
+				if b != `{"name":"NAME","value":"v"}` {
+					t.Errorf("got body %s", b)
+				}
+			}
+		})
+	}
+}
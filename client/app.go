@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appTokenEarlyRefresh makes the installation token be refreshed before it
+	// really expires, in case of the clock skew.
+	appTokenEarlyRefresh = time.Minute
+
+	appJWTLifetime = 9 * time.Minute
+)
+
+// appAuth is the configuration to authenticate as an installation of GitHub App.
+type appAuth struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+}
+
+// WithAppAuth makes the client authenticate as the installation of GitHub App.
+// The installation token is refreshed automatically before it expires, and
+// the token generator passed to NewClient is not used.
+func WithAppAuth(appID int64, privateKeyPEM []byte, installationID int64) ClientOption {
+	return func(o *clientOptions) {
+		key, err := parseRSAPrivateKey(privateKeyPEM)
+		if err != nil {
+			o.appErr = err
+
+			return
+		}
+
+		o.app = &appAuth{
+			appID:          appID,
+			installationID: installationID,
+			key:            key,
+		}
+	}
+}
+
+func parseRSAPrivateKey(v []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(v)
+	if block == nil {
+		return nil, errors.New("invalid private key of GitHub App: not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key of GitHub App: %v", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid private key of GitHub App: not a RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// jwt returns the JSON Web Token signed by the private key of GitHub App.
+func (a *appAuth) jwt() (string, error) {
+	// Issue the token a minute in the past in case of the clock skew.
+	now := time.Now().Add(-time.Minute)
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// jwtTransport authenticates the request as the GitHub App.
+type jwtTransport struct {
+	auth *appAuth
+	base http.RoundTripper
+}
+
+func (t *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.auth.jwt()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
+
+// appTokenSource mints the installation token of GitHub App.
+type appTokenSource struct {
+	auth *appAuth
+	c    *sdk.Client
+}
+
+func newAppTokenSource(o clientOptions) (oauth2.TokenSource, error) {
+	c, err := newAppClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{auth: o.app, c: c}), nil
+}
+
+// newAppClient returns the client which authenticates as the GitHub App itself
+// rather than its installation, which is required by the endpoints under /app.
+func newAppClient(o clientOptions) (*sdk.Client, error) {
+	hc := &http.Client{
+		Transport: &jwtTransport{auth: o.app, base: o.baseTransport()},
+		Timeout:   o.requestTimeout(),
+	}
+
+	if o.baseURL == "" {
+		return sdk.NewClient(hc), nil
+	}
+
+	return sdk.NewEnterpriseClient(o.baseURL, o.uploadURL, hc)
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	t, _, err := s.c.Apps.CreateInstallationToken(ctx, s.auth.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token of GitHub App: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: t.GetToken(),
+		TokenType:   "token",
+		Expiry:      t.GetExpiresAt().Add(-appTokenEarlyRefresh),
+	}, nil
+}
+
+// ListInstallationRepos returns the repos which the installation of GitHub App
+// can access, which may be a subset of the repos of org. The client must
+// authenticate as the installation, see WithAppAuth. The result is cached
+// for a minute.
+func (cl client) ListInstallationRepos() ([]*sdk.Repository, error) {
+	const key = "installation-repos"
+	if v, ok := cl.cache.get(key); ok {
+		return append([]*sdk.Repository(nil), v.([]*sdk.Repository)...), nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var repos []*sdk.Repository
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Apps.ListRepos(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos of installation: %w", err)
+		}
+
+		repos = append(repos, v.Repositories...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	cl.cache.set(key, repos)
+
+	return append([]*sdk.Repository(nil), repos...), nil
+}
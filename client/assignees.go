@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/google/go-github/v36/github"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// AssignIssue assigns the issue or PR to the users. GitHub ignores the users who
+// can't be assigned, such as the ones who are not collaborators, and they are returned.
+func (cl client) AssignIssue(org, repo string, number int, logins []string) ([]string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	issue, _, err := cl.c.Issues.AddAssignees(ctx, org, repo, number, logins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign %s to %v: %w", PRInfo{org, repo, number}, logins, err)
+	}
+
+	assigned := sets.NewString()
+	for _, u := range issue.Assignees {
+		assigned.Insert(strings.ToLower(u.GetLogin()))
+	}
+
+	return notApplied(logins, assigned), nil
+}
+
+func (cl client) UnassignIssue(org, repo string, number int, logins []string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Issues.RemoveAssignees(ctx, org, repo, number, logins); err != nil {
+		return fmt.Errorf("failed to unassign %v from %s: %w", logins, PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// RequestReviewers requests the users and teams to review the PR. GitHub ignores
+// the ones who can't be requested, and they are returned.
+func (cl client) RequestReviewers(org, repo string, number int, users, teams []string) ([]string, []string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr, _, err := cl.c.PullRequests.RequestReviewers(ctx, org, repo, number, sdk.ReviewersRequest{
+		Reviewers:     users,
+		TeamReviewers: teams,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to request reviewers %v and teams %v for %s: %w",
+			users, teams, PRInfo{org, repo, number}, err,
+		)
+	}
+
+	requestedUsers := sets.NewString()
+	for _, u := range pr.RequestedReviewers {
+		requestedUsers.Insert(strings.ToLower(u.GetLogin()))
+	}
+
+	requestedTeams := sets.NewString()
+	for _, t := range pr.RequestedTeams {
+		requestedTeams.Insert(strings.ToLower(t.GetSlug()))
+	}
+
+	return notApplied(users, requestedUsers), notApplied(teams, requestedTeams), nil
+}
+
+func (cl client) RemoveRequestedReviewers(org, repo string, number int, users, teams []string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	_, err := cl.c.PullRequests.RemoveReviewers(ctx, org, repo, number, sdk.ReviewersRequest{
+		Reviewers:     users,
+		TeamReviewers: teams,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"failed to remove reviewers %v and teams %v from %s: %w",
+			users, teams, PRInfo{org, repo, number}, err,
+		)
+	}
+
+	return nil
+}
+
+// notApplied returns the items which are not in the applied set of lower case.
+func notApplied(items []string, applied sets.String) []string {
+	var r []string
+	for _, item := range items {
+		if !applied.Has(strings.ToLower(item)) {
+			r = append(r, item)
+		}
+	}
+
+	return r
+}
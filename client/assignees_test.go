@@ -0,0 +1,122 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAssignIssue(t *testing.T) {
+	cases := []struct {
+		name         string
+		logins       []string
+		response     string
+		wantRejected []string
+	}{
+		{
+			name:     "all assigned",
+			logins:   []string{"alice", "Bob"},
+			response: `{"assignees":[{"login":"alice"},{"login":"bob"}]}`,
+		},
+		{
+			name:         "some are not collaborators",
+			logins:       []string{"alice", "mallory", "eve"},
+			response:     `{"assignees":[{"login":"Alice"},{"login":"carol"}]}`,
+			wantRejected: []string{"mallory", "eve"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, c.response)
+			})
+
+			rejected, err := s.client(t).AssignIssue("owner", "repo", 1, c.logins)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(rejected) != fmt.Sprint(c.wantRejected) {
+				t.Errorf("got rejected %v, want %v", rejected, c.wantRejected)
+			}
+
+			if got, want := s.received(), []string{"POST /repos/owner/repo/issues/1/assignees"}; fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("got requests %v, want %v", got, want)
+			}
+
+			// All the logins are requested, and GitHub applies the subset.
+			var body struct {
+				Assignees []string `json:"assignees"`
+			}
+			if err := json.Unmarshal([]byte(s.bodies[0]), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(body.Assignees) != fmt.Sprint(c.logins) {
+				t.Errorf("got assignees %v in request, want %v", body.Assignees, c.logins)
+			}
+		})
+	}
+}
+
+func TestRequestReviewers(t *testing.T) {
+	cases := []struct {
+		name              string
+		users             []string
+		teams             []string
+		status            int
+		response          string
+		wantRejectedUsers []string
+		wantRejectedTeams []string
+		wantErr           bool
+	}{
+		{
+			name:     "all requested",
+			users:    []string{"alice"},
+			teams:    []string{"Core"},
+			status:   http.StatusCreated,
+			response: `{"requested_reviewers":[{"login":"alice"}],"requested_teams":[{"slug":"core"}]}`,
+		},
+		{
+			name:              "some users and teams are ignored",
+			users:             []string{"alice", "mallory"},
+			teams:             []string{"core", "secret"},
+			status:            http.StatusCreated,
+			response:          `{"requested_reviewers":[{"login":"alice"}],"requested_teams":[{"slug":"core"}]}`,
+			wantRejectedUsers: []string{"mallory"},
+			wantRejectedTeams: []string{"secret"},
+		},
+		{
+			name:     "rejected as a whole",
+			users:    []string{"author"},
+			status:   http.StatusUnprocessableEntity,
+			response: `{"message":"Review cannot be requested from pull request author."}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(c.status)
+				fmt.Fprint(w, c.response)
+			})
+
+			users, teams, err := s.client(t).RequestReviewers("owner", "repo", 1, c.users, c.teams)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if fmt.Sprint(users) != fmt.Sprint(c.wantRejectedUsers) {
+				t.Errorf("got rejected users %v, want %v", users, c.wantRejectedUsers)
+			}
+
+			if fmt.Sprint(teams) != fmt.Sprint(c.wantRejectedTeams) {
+				t.Errorf("got rejected teams %v, want %v", teams, c.wantRejectedTeams)
+			}
+		})
+	}
+}
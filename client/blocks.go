@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// ErrNotOrgAdmin is returned when the authenticated user is not the admin of org
+// or the token lacks the admin:org scope.
+var ErrNotOrgAdmin = errors.New("not admin of org")
+
+// BlockUser blocks the user from the org. It is ok to block a blocked user.
+func (cl client) BlockUser(org, user string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	r, err := cl.c.Organizations.BlockUser(ctx, org, user)
+	if err == nil {
+		return nil
+	}
+
+	if r != nil && r.StatusCode == http.StatusUnprocessableEntity {
+		// Github responds 422 if the user has been blocked.
+		if blocked, _, err1 := cl.c.Organizations.IsBlocked(ctx, org, user); err1 == nil && blocked {
+			return nil
+		}
+	}
+
+	return blockError(fmt.Sprintf("block %s from %s", user, org), r, err)
+}
+
+// UnblockUser unblocks the user from the org. It is ok to unblock a user who
+// is not blocked.
+func (cl client) UnblockUser(org, user string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	r, err := cl.c.Organizations.UnblockUser(ctx, org, user)
+	if err != nil && (r == nil || r.StatusCode != http.StatusNotFound) {
+		return blockError(fmt.Sprintf("unblock %s from %s", user, org), r, err)
+	}
+
+	return nil
+}
+
+// ListBlockedUsers returns all the users blocked from the org.
+func (cl client) ListBlockedUsers(org string) ([]*sdk.User, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var users []*sdk.User
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Organizations.ListBlockedUsers(ctx, org, opt)
+		if err != nil {
+			return nil, blockError(fmt.Sprintf("list blocked users of %s", org), resp, err)
+		}
+
+		users = append(users, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return users, nil
+}
+
+// blockError returns the error of operation on the blocked users of org.
+func blockError(op string, resp *sdk.Response, err error) error {
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to %s: %w: %v", op, ErrNotOrgAdmin, err)
+	}
+
+	return fmt.Errorf("failed to %s: %w", op, err)
+}
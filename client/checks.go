@@ -0,0 +1,578 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// maxAnnotationsPerRequest is the max number of annotations GitHub accepts in a request.
+const maxAnnotationsPerRequest = 50
+
+// CheckRunOptions describes the check run to set.
+type CheckRunOptions struct {
+	// Name is the name of check run, which identifies it on the commit.
+	Name string
+	// Status is one of "queued", "in_progress" and "completed".
+	// It is "completed" if Conclusion is set.
+	Status string
+	// Conclusion is one of "success", "failure", "neutral", "cancelled",
+	// "skipped", "timed_out" and "action_required".
+	Conclusion string
+	DetailsURL string
+
+	// Title and Summary are required if there are annotations.
+	Title       string
+	Summary     string
+	Text        string
+	Annotations []*sdk.CheckRunAnnotation
+
+	// AppID is the id of GitHub App which creates the check run. Only the check
+	// run of the same name created by the app is updated, because the check run
+	// of other apps can't be updated. It is the app of WithAppAuth if zero, and
+	// it is required if the client doesn't authenticate as a GitHub App.
+	AppID int64
+}
+
+func (cl client) CreateStatus(org, repo, sha string, status sdk.RepoStatus) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Repositories.CreateStatus(ctx, org, repo, sha, &status); err != nil {
+		return fmt.Errorf("failed to create status %s on %s/%s@%s: %w", status.GetContext(), org, repo, sha, err)
+	}
+
+	return nil
+}
+
+// SetCheckRun creates the check run on the commit, or updates it if the check
+// run of the same name created by the same app exists, see CheckRunOptions.AppID.
+// The annotations are sent in chunks of 50, which is the limit of GitHub.
+func (cl client) SetCheckRun(org, repo, sha string, opt CheckRunOptions) (*sdk.CheckRun, error) {
+	if opt.Name == "" {
+		return nil, errors.New("missing name of check run")
+	}
+
+	if len(opt.Annotations) > 0 && (opt.Title == "" || opt.Summary == "") {
+		return nil, errors.New("missing title or summary of check run with annotations")
+	}
+
+	appID := opt.AppID
+	if appID == 0 {
+		appID = cl.appID
+	}
+
+	if appID == 0 {
+		return nil, errors.New("missing id of GitHub App which creates the check run")
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	existing, err := cl.findCheckRun(ctx, org, repo, sha, opt.Name, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := opt.Annotations
+	chunk := annotations
+	if len(chunk) > maxAnnotationsPerRequest {
+		chunk = chunk[:maxAnnotationsPerRequest]
+	}
+	annotations = annotations[len(chunk):]
+
+	update := sdk.UpdateCheckRunOptions{
+		Name:   opt.Name,
+		Output: opt.output(chunk),
+	}
+	if opt.DetailsURL != "" {
+		update.DetailsURL = sdk.String(opt.DetailsURL)
+	}
+	if opt.Conclusion != "" {
+		update.Status = sdk.String("completed")
+		update.Conclusion = sdk.String(opt.Conclusion)
+		update.CompletedAt = &sdk.Timestamp{Time: time.Now()}
+	} else if opt.Status != "" {
+		update.Status = sdk.String(opt.Status)
+	}
+
+	var run *sdk.CheckRun
+	if existing != nil {
+		run, _, err = cl.c.Checks.UpdateCheckRun(ctx, org, repo, existing.GetID(), update)
+	} else {
+		run, _, err = cl.c.Checks.CreateCheckRun(ctx, org, repo, sdk.CreateCheckRunOptions{
+			Name:        update.Name,
+			HeadSHA:     sha,
+			DetailsURL:  update.DetailsURL,
+			Status:      update.Status,
+			Conclusion:  update.Conclusion,
+			CompletedAt: update.CompletedAt,
+			Output:      update.Output,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set check run %s on %s/%s@%s: %w", opt.Name, org, repo, sha, err)
+	}
+
+	// The annotations are appended to the check run by each update.
+	for len(annotations) > 0 {
+		chunk = annotations
+		if len(chunk) > maxAnnotationsPerRequest {
+			chunk = chunk[:maxAnnotationsPerRequest]
+		}
+		annotations = annotations[len(chunk):]
+
+		run, _, err = cl.c.Checks.UpdateCheckRun(ctx, org, repo, run.GetID(), sdk.UpdateCheckRunOptions{
+			Name:   opt.Name,
+			Output: opt.output(chunk),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add annotations to check run %s on %s/%s@%s: %w", opt.Name, org, repo, sha, err)
+		}
+	}
+
+	return run, nil
+}
+
+// findCheckRun returns the check run of the name created by the app on the
+// commit, or nil if there is none.
+func (cl client) findCheckRun(ctx context.Context, org, repo, sha, name string, appID int64) (*sdk.CheckRun, error) {
+	opt := &sdk.ListCheckRunsOptions{
+		CheckName:   sdk.String(name),
+		ListOptions: sdk.ListOptions{Page: 1, PerPage: 100},
+	}
+
+	for {
+		runs, resp, err := cl.c.Checks.ListCheckRunsForRef(ctx, org, repo, sha, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs of %s/%s@%s: %w", org, repo, sha, err)
+		}
+
+		for _, v := range runs.CheckRuns {
+			if v.GetName() == name && v.GetApp().GetID() == appID {
+				return v, nil
+			}
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			return nil, nil
+		}
+
+		opt.Page = page
+	}
+}
+
+func (opt *CheckRunOptions) output(annotations []*sdk.CheckRunAnnotation) *sdk.CheckRunOutput {
+	if opt.Title == "" && opt.Summary == "" {
+		return nil
+	}
+
+	o := &sdk.CheckRunOutput{
+		Title:       sdk.String(opt.Title),
+		Summary:     sdk.String(opt.Summary),
+		Annotations: annotations,
+	}
+	if opt.Text != "" {
+		o.Text = sdk.String(opt.Text)
+	}
+
+	return o
+}
+
+const (
+	// StateSuccess means the context succeeded.
+	StateSuccess = "success"
+	// StateFailure means the context failed or errored.
+	StateFailure = "failure"
+	// StatePending means the context is not finished.
+	StatePending = "pending"
+)
+
+// ContextResult is the normalized result of a commit status or a check run.
+type ContextResult struct {
+	// Name is the context of status or the name of check run.
+	Name string
+	// State is one of StateSuccess, StateFailure and StatePending.
+	State string
+	// Source is "status" or "check_run".
+	Source string
+	URL    string
+}
+
+// GetCombinedState returns the rollup of both the commit statuses and the check
+// runs of the ref, which is StateFailure if any of them fails, StatePending if
+// any of them is not finished or there is none of them, and StateSuccess otherwise.
+// If a status and a check run have the same name, the worse one is kept.
+// The contexts are sorted by name.
+func (cl client) GetCombinedState(org, repo, ref string) (string, []ContextResult, error) {
+	statuses, err := cl.listStatusResults(org, repo, ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	runs, err := cl.listCheckRunResults(org, repo, ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	m := map[string]ContextResult{}
+	for _, items := range [][]ContextResult{statuses, runs} {
+		for _, v := range items {
+			if old, ok := m[v.Name]; !ok || stateRank(v.State) > stateRank(old.State) {
+				m[v.Name] = v
+			}
+		}
+	}
+
+	contexts := make([]ContextResult, 0, len(m))
+	state := StateSuccess
+	for _, v := range m {
+		contexts = append(contexts, v)
+
+		if stateRank(v.State) > stateRank(state) {
+			state = v.State
+		}
+	}
+
+	if len(contexts) == 0 {
+		state = StatePending
+	}
+
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+
+	return state, contexts, nil
+}
+
+func (cl client) listStatusResults(org, repo, ref string) ([]ContextResult, error) {
+	statuses, err := cl.listCombinedStatuses(org, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]ContextResult, 0, len(statuses))
+	for _, s := range statuses {
+		state := StatePending
+		switch s.GetState() {
+		case "success":
+			state = StateSuccess
+
+		case "failure", "error":
+			state = StateFailure
+		}
+
+		r = append(r, ContextResult{
+			Name:   s.GetContext(),
+			State:  state,
+			Source: "status",
+			URL:    s.GetTargetURL(),
+		})
+	}
+
+	return r, nil
+}
+
+// listCombinedStatuses returns the latest status of each context on the ref,
+// which are read from all the pages of the combined status.
+func (cl client) listCombinedStatuses(org, repo, ref string) ([]*sdk.RepoStatus, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var r []*sdk.RepoStatus
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Repositories.GetCombinedStatus(ctx, org, repo, ref, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get combined status of %s/%s@%s: %w", org, repo, ref, err)
+		}
+
+		r = append(r, v.Statuses...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return r, nil
+}
+
+func (cl client) listCheckRunResults(org, repo, ref string) ([]ContextResult, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var r []ContextResult
+
+	opt := &sdk.ListCheckRunsOptions{ListOptions: sdk.ListOptions{Page: 1, PerPage: 100}}
+	for {
+		v, resp, err := cl.c.Checks.ListCheckRunsForRef(ctx, org, repo, ref, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs of %s/%s@%s: %w", org, repo, ref, err)
+		}
+
+		for _, run := range v.CheckRuns {
+			state := StatePending
+			if run.GetStatus() == "completed" {
+				switch run.GetConclusion() {
+				case "success", "neutral", "skipped":
+					state = StateSuccess
+
+				default:
+					state = StateFailure
+				}
+			}
+
+			r = append(r, ContextResult{
+				Name:   run.GetName(),
+				State:  state,
+				Source: "check_run",
+				URL:    run.GetHTMLURL(),
+			})
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return r, nil
+}
+
+// stateRank returns the rank of state, the worse one has the bigger rank.
+func stateRank(state string) int {
+	switch state {
+	case StateFailure:
+		return 2
+
+	case StatePending:
+		return 1
+
+	default:
+		return 0
+	}
+}
+
+// ErrNotCheckOwner is returned when rerequesting the check suite or run which
+// is created by another app. Github only allows the app which created it to
+// rerequest it.
+var ErrNotCheckOwner = errors.New("the check is created by another app")
+
+// ListCheckSuitesForRef returns all the check suites of the ref, which may be
+// a sha, branch or tag.
+func (cl client) ListCheckSuitesForRef(org, repo, ref string) ([]*sdk.CheckSuite, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var r []*sdk.CheckSuite
+
+	opt := &sdk.ListCheckSuiteOptions{ListOptions: sdk.ListOptions{Page: 1, PerPage: 100}}
+	for {
+		v, resp, err := cl.c.Checks.ListCheckSuitesForRef(ctx, org, repo, ref, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check suites of %s/%s@%s: %w", org, repo, ref, err)
+		}
+
+		r = append(r, v.CheckSuites...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return r, nil
+}
+
+// RerequestCheckSuite triggers all the check runs of the check suite again.
+// It returns ErrNotCheckOwner if the check suite is created by another app.
+func (cl client) RerequestCheckSuite(org, repo string, checkSuiteID int64) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	resp, err := cl.c.Checks.ReRequestCheckSuite(ctx, org, repo, checkSuiteID)
+
+	return rerequestError(fmt.Sprintf("check suite %d of %s/%s", checkSuiteID, org, repo), resp, err)
+}
+
+// RerequestCheckRun triggers the check run again. It returns ErrNotCheckOwner
+// if the check run is created by another app.
+func (cl client) RerequestCheckRun(org, repo string, checkRunID int64) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	req, err := cl.c.NewRequest("POST", fmt.Sprintf("repos/%s/%s/check-runs/%d/rerequest", org, repo, checkRunID), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cl.c.Do(ctx, req, nil)
+
+	return rerequestError(fmt.Sprintf("check run %d of %s/%s", checkRunID, org, repo), resp, err)
+}
+
+// RerequestFailedCheckSuites rerequests the completed check suites of the ref
+// which are not successful, and returns the ids of them. The check suites which
+// are created by other apps are skipped, because they can't be rerequested by
+// this one.
+func (cl client) RerequestFailedCheckSuites(org, repo, ref string) ([]int64, error) {
+	suites, err := cl.ListCheckSuitesForRef(org, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, s := range suites {
+		if s.GetStatus() != "completed" {
+			continue
+		}
+
+		switch s.GetConclusion() {
+		case "success", "neutral", "skipped":
+			continue
+		}
+
+		err := cl.RerequestCheckSuite(org, repo, s.GetID())
+		if errors.Is(err, ErrNotCheckOwner) {
+			continue
+		}
+
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, s.GetID())
+	}
+
+	return ids, nil
+}
+
+func rerequestError(target string, resp *sdk.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to rerequest %s: %w: %v", target, ErrNotCheckOwner, err)
+	}
+
+	return fmt.Errorf("failed to rerequest %s: %w", target, err)
+}
+
+// setStatusesConcurrency is the max number of statuses created concurrently by SetStatuses.
+const setStatusesConcurrency = 4
+
+// SetStatuses creates the statuses on the commit concurrently. The status of
+// which the context already has the same state, description and target url is
+// skipped, so that no redundant status webhook is triggered. If several statuses
+// have the same context, the last one is created.
+func (cl client) SetStatuses(org, repo, sha string, statuses []sdk.RepoStatus) error {
+	current, err := cl.listStatuses(org, repo, sha)
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i := range statuses {
+		index[statuses[i].GetContext()] = i
+	}
+
+	var todo []sdk.RepoStatus
+	for i := range statuses {
+		s := &statuses[i]
+		if index[s.GetContext()] != i {
+			continue
+		}
+
+		if c, ok := current[s.GetContext()]; ok &&
+			c.GetState() == s.GetState() &&
+			c.GetDescription() == s.GetDescription() &&
+			c.GetTargetURL() == s.GetTargetURL() {
+			continue
+		}
+
+		todo = append(todo, *s)
+	}
+
+	errs := make([]error, len(todo))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < setStatusesConcurrency && i < len(todo); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				errs[i] = cl.CreateStatus(org, repo, sha, todo[i])
+			}
+		}()
+	}
+
+	for i := range todo {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to set %d of %d statuses: %w", len(failed), len(todo), failed[0])
+	}
+
+	return nil
+}
+
+// listStatuses returns the latest status of each context on the ref.
+func (cl client) listStatuses(org, repo, ref string) (map[string]*sdk.RepoStatus, error) {
+	statuses, err := cl.listCombinedStatuses(org, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]*sdk.RepoStatus, len(statuses))
+	for _, s := range statuses {
+		r[s.GetContext()] = s
+	}
+
+	return r, nil
+}
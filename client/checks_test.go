@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+func TestSetCheckRun(t *testing.T) {
+	annotations := make([]*sdk.CheckRunAnnotation, 120)
+	for i := range annotations {
+		annotations[i] = &sdk.CheckRunAnnotation{Path: sdk.String("a.go"), Message: sdk.String(fmt.Sprint(i))}
+	}
+
+	cases := []struct {
+		name            string
+		opt             CheckRunOptions
+		pages           []string
+		wantRequests    []string
+		wantAnnotations []int
+		wantErr         bool
+	}{
+		{
+			name: "update the run of app on a later page",
+			opt:  CheckRunOptions{Name: "ci", Conclusion: "success", AppID: 10},
+			pages: []string{
+				`{"check_runs":[{"id":1,"name":"ci","app":{"id":2}}]}`,
+				`{"check_runs":[{"id":5,"name":"ci","app":{"id":10}}]}`,
+			},
+			wantRequests: []string{
+				"GET /repos/owner/repo/commits/sha/check-runs",
+				"GET /repos/owner/repo/commits/sha/check-runs",
+				"PATCH /repos/owner/repo/check-runs/5",
+			},
+		},
+		{
+			name:  "create if only other apps have the run",
+			opt:   CheckRunOptions{Name: "ci", Status: "in_progress", AppID: 10},
+			pages: []string{`{"check_runs":[{"id":1,"name":"ci","app":{"id":2}}]}`},
+			wantRequests: []string{
+				"GET /repos/owner/repo/commits/sha/check-runs",
+				"POST /repos/owner/repo/check-runs",
+			},
+		},
+		{
+			name: "annotations in chunks of 50",
+			opt: CheckRunOptions{
+				Name: "lint", Conclusion: "failure", Title: "lint", Summary: "120 issues",
+				Annotations: annotations, AppID: 10,
+			},
+			pages: []string{`{"check_runs":[]}`},
+			wantRequests: []string{
+				"GET /repos/owner/repo/commits/sha/check-runs",
+				"POST /repos/owner/repo/check-runs",
+				"PATCH /repos/owner/repo/check-runs/7",
+				"PATCH /repos/owner/repo/check-runs/7",
+			},
+			wantAnnotations: []int{50, 50, 20},
+		},
+		{
+			name:    "missing app id",
+			opt:     CheckRunOptions{Name: "ci", Conclusion: "success"},
+			wantErr: true,
+		},
+		{
+			name:    "annotations without summary",
+			opt:     CheckRunOptions{Name: "ci", Annotations: annotations[:1], AppID: 10},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var s *testServer
+			s = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					fmt.Fprint(w, `{"id":7}`)
+
+					return
+				}
+
+				s.writePage(w, r, c.pages)
+			})
+
+			_, err := s.client(t).SetCheckRun("owner", "repo", "sha", c.opt)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Fatalf("got requests %v, want %v", got, c.wantRequests)
+			}
+
+			if c.wantAnnotations == nil {
+				return
+			}
+
+			var got []int
+			for _, b := range s.bodies[1:] {
+				var v struct {
+					Output struct {
+						Annotations []json.RawMessage `json:"annotations"`
+					} `json:"output"`
+				}
+				if err := json.Unmarshal([]byte(b), &v); err != nil {
+					t.Fatal(err)
+				}
+
+				got = append(got, len(v.Output.Annotations))
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(c.wantAnnotations) {
+				t.Errorf("got annotations per request %v, want %v", got, c.wantAnnotations)
+			}
+		})
+	}
+}
+
+func TestSetStatusesSkipsUnchanged(t *testing.T) {
+	pages := []string{
+		`{"statuses":[{"context":"build","state":"success","description":"ok"}]}`,
+		`{"statuses":[{"context":"lint","state":"pending"}]}`,
+	}
+
+	var s *testServer
+	s = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			s.writePage(w, r, pages)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+
+	err := s.client(t).SetStatuses("owner", "repo", "sha", []sdk.RepoStatus{
+		{Context: sdk.String("build"), State: sdk.String("success"), Description: sdk.String("ok")},
+		{Context: sdk.String("lint"), State: sdk.String("failure")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"GET /repos/owner/repo/commits/sha/status",
+		"GET /repos/owner/repo/commits/sha/status",
+		"POST /repos/owner/repo/statuses/sha",
+	}
+	if got := s.received(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+
+	if !strings.Contains(s.bodies[2], `"context":"lint"`) {
+		t.Errorf("got body %s, want the status of lint", s.bodies[2])
+	}
+}
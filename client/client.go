@@ -2,25 +2,381 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	sdk "github.com/google/go-github/v36/github"
 	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
-func NewClient(getToken func() []byte) Client {
-	ts := oauth2.StaticTokenSource(&oauth2.Token{
-		AccessToken: string(getToken()),
-	})
-	tc := oauth2.NewClient(context.Background(), ts)
+// ClientOption configures the client.
+type ClientOption func(*clientOptions)
 
-	return client{sdk.NewClient(tc)}
+type clientOptions struct {
+	retryMaxAttempts int
+	retryBaseDelay   time.Duration
+	retryMaxElapsed  time.Duration
+
+	baseURL   string
+	uploadURL string
+
+	app    *appAuth
+	appErr error
+
+	etagStore ETagStore
+
+	dryRun bool
+
+	rateLimitThreshold int
+
+	httpClient  *http.Client
+	timeout     time.Duration
+	callTimeout time.Duration
+}
+
+// baseTransport returns the transport under the ones of this package, which is
+// the one of the http client set by WithHTTPClient if any.
+func (o *clientOptions) baseTransport() http.RoundTripper {
+	if o.httpClient != nil && o.httpClient.Transport != nil {
+		return o.httpClient.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+// requestTimeout returns the timeout of a request, see WithTimeout.
+func (o *clientOptions) requestTimeout() time.Duration {
+	switch {
+	case o.timeout > 0:
+		return o.timeout
+
+	case o.httpClient != nil && o.httpClient.Timeout > 0:
+		return o.httpClient.Timeout
+
+	default:
+		return defaultRequestTimeout
+	}
+}
+
+// wholeCallTimeout returns the timeout of a whole call of method, see
+// WithCallTimeout. By default, it is long enough for a request and the retries
+// allowed by WithRetryMaxElapsed, and at least one minute.
+func (o *clientOptions) wholeCallTimeout() time.Duration {
+	if o.callTimeout > 0 {
+		return o.callTimeout
+	}
+
+	d := defaultTimeout
+	if v := o.requestTimeout(); v > d {
+		d = v
+	}
+
+	if o.retryMaxElapsed > d {
+		d = o.retryMaxElapsed
+	}
+
+	return d
+}
+
+// validate checks the options.
+func (o *clientOptions) validate() error {
+	if o.appErr != nil {
+		return o.appErr
+	}
+
+	if o.baseURL == "" && o.uploadURL == "" {
+		return nil
+	}
+
+	for _, v := range []string{o.baseURL, o.uploadURL} {
+		u, err := url.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid url %q: %v", v, err)
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid url %q: missing scheme or host", v)
+		}
+
+		if !strings.HasSuffix(u.Path, "/") {
+			return fmt.Errorf("invalid url %q: it must have a trailing slash", v)
+		}
+	}
+
+	return nil
+}
+
+// WithRetry makes the client retry the request at most maxAttempts times
+// on the secondary rate limit and the 502/503/504 errors. The delay between
+// retries grows exponentially from baseDelay, unless the Retry-After header
+// says how long to wait. It can be overridden per call, see Client.WithCallOptions.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.retryMaxAttempts = maxAttempts
+		o.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRetryMaxElapsed sets the max time spent on a call including the retries,
+// see WithRetry. Zero means no limit other than the timeout of call.
+func WithRetryMaxElapsed(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.retryMaxElapsed = d
+	}
+}
+
+// WithRateLimitThreshold sets the remaining calls of core API below which
+// WaitForRateLimit blocks until the rate limit resets. It is 100 by default.
+func WithRateLimitThreshold(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.rateLimitThreshold = n
+	}
+}
+
+// WithHTTPClient makes the client send the requests by the transport of hc, for
+// example the one with a proxy or custom TLS config. The authentication, retry
+// and ETag cache of this package are on top of it. The timeout of hc is used if
+// WithTimeout is not set.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = hc
+	}
+}
+
+// WithTimeout sets the timeout of a http request including the retries, see
+// WithRetry. It is 30 seconds by default. A call of method may send several
+// requests, such as one per page of a list, and the whole call is bounded by
+// the timeout set by WithCallTimeout besides, so a request fails as soon as
+// either of them expires.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithCallTimeout sets the timeout of a whole call of method, including all the
+// http requests it sends and their retries. By default, it is the longest of one
+// minute, the timeout set by WithTimeout and the one set by WithRetryMaxElapsed.
+func WithCallTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.callTimeout = d
+	}
+}
+
+// WithBaseURL makes the client target the GitHub Enterprise Server, for example
+// "https://ghe.company.com/api/v3/" and "https://ghe.company.com/api/uploads/".
+// Both of the urls must have a trailing slash.
+func WithBaseURL(apiURL, uploadURL string) ClientOption {
+	return func(o *clientOptions) {
+		o.baseURL = apiURL
+		o.uploadURL = uploadURL
+	}
+}
+
+// NewClient returns a Client. The invalid options are logged and ignored,
+// use NewClientE to get the error instead.
+func NewClient(getToken func() []byte, opts ...ClientOption) Client {
+	c, err := NewClientE(getToken, ignoreInvalidOptions(opts)...)
+	if err != nil {
+		defaultLogger.Error("failed to create the client, the default one is used", LogFields{"error": err.Error()})
+
+		c, _ = NewClientE(getToken)
+	}
+
+	return c
+}
+
+// NewClientE is the same as NewClient except that it returns the error
+// of invalid options.
+func NewClientE(getToken func() []byte, opts ...ClientOption) (Client, error) {
+	o, err := newClientOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tc, rate, err := newHTTPClient(getToken, o)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := client{
+		rate:          rate,
+		cache:         newTTLCache(permissionCacheTTL),
+		rateThreshold: o.rateLimitThreshold,
+		timeout:       o.wholeCallTimeout(),
+	}
+
+	if cli.rateThreshold <= 0 {
+		cli.rateThreshold = defaultRateLimitThreshold
+	}
+
+	if o.app != nil {
+		cli.appID = o.app.appID
+
+		if cli.app, err = newAppClient(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.baseURL == "" {
+		cli.c = sdk.NewClient(tc)
+
+		return cli, nil
+	}
+
+	if cli.c, err = sdk.NewEnterpriseClient(o.baseURL, o.uploadURL, tc); err != nil {
+		return nil, err
+	}
+
+	return cli, nil
+}
+
+func newClientOptions(opts []ClientOption) (clientOptions, error) {
+	o := clientOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o, o.validate()
+}
+
+// ignoreInvalidOptions returns the options except the invalid ones, which are
+// logged. An option is invalid if it fails the validation together with the
+// valid ones before it.
+func ignoreInvalidOptions(opts []ClientOption) []ClientOption {
+	if _, err := newClientOptions(opts); err == nil {
+		return opts
+	}
+
+	valid := make([]ClientOption, 0, len(opts))
+	for i, opt := range opts {
+		if _, err := newClientOptions(append(valid, opt)); err != nil {
+			defaultLogger.Error("the invalid option of client is ignored", LogFields{
+				"index": i,
+				"error": err.Error(),
+			})
+
+			continue
+		}
+
+		valid = append(valid, opt)
+	}
+
+	return valid
+}
+
+// newHTTPClient returns the http client shared by the REST and GraphQL clients.
+func newHTTPClient(getToken func() []byte, o clientOptions) (*http.Client, *rateRecorder, error) {
+	var ts oauth2.TokenSource
+	if o.app != nil {
+		v, err := newAppTokenSource(o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ts = v
+	} else {
+		ts = oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: string(getToken()),
+		})
+	}
+
+	// The etag transport is under the oauth2 one, so that it sees the
+	// credential of request.
+	base := o.baseTransport()
+	if o.etagStore != nil {
+		base = &etagTransport{base: base, store: o.etagStore}
+	}
+
+	tc := &http.Client{
+		Transport: &oauth2.Transport{Source: ts, Base: base},
+		Timeout:   o.requestTimeout(),
+	}
+
+	if o.httpClient != nil {
+		tc.CheckRedirect = o.httpClient.CheckRedirect
+		tc.Jar = o.httpClient.Jar
+	}
+
+	rate := &rateRecorder{}
+	tc.Transport = &rateLimitTransport{base: tc.Transport, recorder: rate}
+
+	// The retry transport is always installed, so that the retry can be
+	// enabled by the call options even if it is disabled by default.
+	tc.Transport = &retryTransport{
+		base: tc.Transport,
+		policy: retryPolicy{
+			maxAttempts: o.retryMaxAttempts,
+			baseDelay:   o.retryBaseDelay,
+			maxElapsed:  o.retryMaxElapsed,
+		},
+	}
+
+	if o.dryRun {
+		tc.Transport = &dryRunTransport{base: tc.Transport}
+	}
+
+	return tc, rate, nil
 }
 
+// defaultTimeout is the least timeout of a whole call of method, see WithCallTimeout.
+const defaultTimeout = time.Minute
+
+// defaultRequestTimeout is the default timeout of a http request, see WithTimeout.
+const defaultRequestTimeout = 30 * time.Second
+
+// defaultRateLimitThreshold is the default threshold of WaitForRateLimit.
+const defaultRateLimitThreshold = 100
+
 type client struct {
 	c *sdk.Client
+
+	rate  *rateRecorder
+	cache *ttlCache
+
+	rateThreshold int
+
+	// timeout is the timeout of a whole call of method, see WithCallTimeout.
+	timeout time.Duration
+
+	// appID is the id of GitHub App which the client authenticates as, see WithAppAuth.
+	appID int64
+	// app is the client which authenticates as the GitHub App itself.
+	app *sdk.Client
+
+	callOpts []CallOption
+}
+
+// newContext returns a context which will be canceled after the timeout of call,
+// and it is shared by all the requests of a call, for example all the pages of
+// a list. It carries the call options of client.
+func (cl client) newContext() (context.Context, context.CancelFunc) {
+	return cl.newContextFrom(context.Background())
+}
+
+// newContextFrom is the same as newContext except that the context is derived
+// from parent, so that the call stops when parent is canceled.
+func (cl client) newContextFrom(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(withCallOptions(parent, cl.callOpts), cl.timeout)
+}
+
+// WithCallOptions returns a client of which the calls take the options, which
+// override the retry configured by WithRetry, for example:
+//
+//	cli.WithCallOptions(client.WithMaxAttempts(2)).CreateComment(org, repo, number, comment)
+//
+// The calls of the methods which don't take the timeout of default are not
+// affected.
+func (cl client) WithCallOptions(opts ...CallOption) Client {
+	cl.callOpts = append(append([]CallOption(nil), cl.callOpts...), opts...)
+
+	return cl
 }
 
 func (cl client) AddPRLabel(pr PRInfo, label string) error {
@@ -109,7 +465,7 @@ func (cl client) GetPRCommits(pr PRInfo) ([]*sdk.RepositoryCommit, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.PullRequests.ListCommits(context.Background(), pr.Org, pr.Repo, pr.Number, nil)
+			v, resp, err := cl.c.PullRequests.ListCommits(context.Background(), pr.Org, pr.Repo, pr.Number, opt)
 			if err != nil {
 				return err
 			}
@@ -878,10 +1234,21 @@ func (cl client) GetUserPermissionOfRepo(org, repo, user string) (*sdk.Repositor
 	return permission, nil
 }
 
+// CreateIssue creates the issue with the title, body, labels, assignees and
+// milestone of request at once, so that only one webhook is fired. Github
+// responds 422 if any of the labels, assignees or milestone doesn't exist,
+// and the field errors can be got by AsGitHubError.
 func (cl client) CreateIssue(org, repo string, request *sdk.IssueRequest) (*sdk.Issue, error) {
-	is, _, err := cl.c.Issues.Create(context.Background(), org, repo, request)
+	if request == nil || request.GetTitle() == "" {
+		return nil, errors.New("missing title of issue")
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	is, _, err := cl.c.Issues.Create(ctx, org, repo, request)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create issue %q in %s/%s: %w", request.GetTitle(), org, repo, err)
 	}
 
 	return is, nil
@@ -1043,3 +1410,198 @@ func (cl client) ListOrg() ([]string, error) {
 
 	return r, nil
 }
+
+func (cl client) CreateComment(org, repo string, number int, comment string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	ic := sdk.IssueComment{
+		Body: sdk.String(comment),
+	}
+	if _, _, err := cl.c.Issues.CreateComment(ctx, org, repo, number, &ic); err != nil {
+		return fmt.Errorf("failed to create comment on %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+func (cl client) ListPullRequestFiles(org, repo string, number int) ([]*sdk.CommitFile, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var files []*sdk.CommitFile
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.PullRequests.ListFiles(ctx, org, repo, number, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		files = append(files, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return files, nil
+}
+
+func (cl client) AddLabel(org, repo string, number int, label string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Issues.AddLabelsToIssue(ctx, org, repo, number, []string{label}); err != nil {
+		return fmt.Errorf("failed to add label %s to %s: %w", label, PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// RemoveLabel removes the label. It is not an error if the label doesn't exist.
+func (cl client) RemoveLabel(org, repo string, number int, label string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	r, err := cl.c.Issues.RemoveLabelForIssue(ctx, org, repo, number, label)
+	if err != nil && !(r != nil && r.StatusCode == http.StatusNotFound) {
+		return fmt.Errorf("failed to remove label %s from %s: %w", label, PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// ReplaceLabels makes the labels of issue or PR to be the specified ones.
+// It only adds the missing labels and removes the redundant ones, so that
+// the labels which are kept will not trigger the label events.
+func (cl client) ReplaceLabels(org, repo string, number int, labels []string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr := PRInfo{org, repo, number}
+	current := sets.NewString()
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Issues.ListLabelsByIssue(ctx, org, repo, number, opt)
+		if err != nil {
+			return fmt.Errorf("failed to list labels of %s: %w", pr, err)
+		}
+
+		for _, l := range v {
+			current.Insert(l.GetName())
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	desired := sets.NewString(labels...)
+
+	if toAdd := desired.Difference(current); toAdd.Len() > 0 {
+		if _, _, err := cl.c.Issues.AddLabelsToIssue(ctx, org, repo, number, toAdd.List()); err != nil {
+			return fmt.Errorf("failed to add labels %v to %s: %w", toAdd.List(), pr, err)
+		}
+	}
+
+	for _, label := range current.Difference(desired).List() {
+		r, err := cl.c.Issues.RemoveLabelForIssue(ctx, org, repo, number, label)
+		if err != nil && !(r != nil && r.StatusCode == http.StatusNotFound) {
+			return fmt.Errorf("failed to remove label %s from %s: %w", label, pr, err)
+		}
+	}
+
+	return nil
+}
+
+func (cl client) RateLimits() (*sdk.RateLimits, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	limits, _, err := cl.c.RateLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate limits: %w", err)
+	}
+
+	return limits, nil
+}
+
+// RemainingCore returns the remaining calls of core API and the time when it resets.
+func (cl client) RemainingCore() (int, time.Time, error) {
+	limits, err := cl.RateLimits()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	core := limits.GetCore()
+	if core == nil {
+		return 0, time.Time{}, errors.New("no core rate limit in the response")
+	}
+
+	return core.Remaining, core.Reset.Time, nil
+}
+
+// LastRate returns the rate limit of core API carried by the latest response
+// without calling the API. It returns false if no response has been seen yet.
+func (cl client) LastRate() (sdk.Rate, bool) {
+	return cl.rate.get()
+}
+
+// WaitForRateLimit blocks until the rate limit of core API resets if the remaining
+// calls are below the threshold, see WithRateLimitThreshold. The batch bots can
+// call it between the operations to avoid exceeding the limit. The rate limit of
+// the latest response is used if any, so that no call is spent on checking it.
+// It returns the error of ctx if ctx is done before the reset.
+func (cl client) WaitForRateLimit(ctx context.Context) error {
+	rate, ok := cl.rate.get()
+	if !ok {
+		remaining, reset, err := cl.RemainingCore()
+		if err != nil {
+			return err
+		}
+
+		rate = sdk.Rate{Remaining: remaining, Reset: sdk.Timestamp{Time: reset}}
+	}
+
+	return waitForReset(ctx, rate, cl.rateThreshold, time.Now)
+}
+
+// waitForReset waits until the reset of rate if the remaining calls are below
+// the threshold. The rate which has been reset is ignored.
+func waitForReset(ctx context.Context, rate sdk.Rate, threshold int, now func() time.Time) error {
+	if rate.Remaining >= threshold {
+		return nil
+	}
+
+	wait := rate.Reset.Time.Sub(now())
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// testServer is a fake github which records the requests it receives.
+type testServer struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	requests []string
+	bodies   []string
+}
+
+// newTestServer returns a testServer which responds the requests by handler.
+// The requests are recorded as "METHOD /path", and the path is without the
+// prefix of GitHub Enterprise Server which the client adds.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		s.lock.Lock()
+		s.requests = append(s.requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v3"))
+		s.bodies = append(s.bodies, strings.TrimSpace(string(b)))
+		s.lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// client returns a Client targeting the server.
+func (s *testServer) client(t *testing.T, opts ...ClientOption) Client {
+	opts = append([]ClientOption{WithBaseURL(s.URL+"/", s.URL+"/")}, opts...)
+
+	c, err := NewClientE(func() []byte { return []byte("token") }, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func (s *testServer) received() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// writePage responds the page of r among pages, which is 1 if the request has
+// no page parameter, with the Link header to the next page if any.
+func (s *testServer) writePage(w http.ResponseWriter, r *http.Request, pages []string) {
+	page := 1
+	fmt.Sscan(r.URL.Query().Get("page"), &page)
+
+	if page < len(pages) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, s.URL, r.URL.Path, page+1))
+	}
+
+	fmt.Fprint(w, pages[page-1])
+}
+
+func TestLabels(t *testing.T) {
+	cases := []struct {
+		name         string
+		call         func(Client) error
+		current      string
+		removeStatus int
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			name: "add label",
+			call: func(c Client) error { return c.AddLabel("owner", "repo", 1, "bug") },
+			wantRequests: []string{
+				"POST /repos/owner/repo/issues/1/labels",
+			},
+		},
+		{
+			name:         "remove label",
+			call:         func(c Client) error { return c.RemoveLabel("owner", "repo", 1, "bug") },
+			removeStatus: http.StatusOK,
+			wantRequests: []string{
+				"DELETE /repos/owner/repo/issues/1/labels/bug",
+			},
+		},
+		{
+			name:         "remove missing label",
+			call:         func(c Client) error { return c.RemoveLabel("owner", "repo", 1, "bug") },
+			removeStatus: http.StatusNotFound,
+			wantRequests: []string{
+				"DELETE /repos/owner/repo/issues/1/labels/bug",
+			},
+		},
+		{
+			name:         "remove label fails",
+			call:         func(c Client) error { return c.RemoveLabel("owner", "repo", 1, "bug") },
+			removeStatus: http.StatusForbidden,
+			wantRequests: []string{
+				"DELETE /repos/owner/repo/issues/1/labels/bug",
+			},
+			wantErr: true,
+		},
+		{
+			name:         "replace labels with the minimal changes",
+			call:         func(c Client) error { return c.ReplaceLabels("owner", "repo", 1, []string{"b", "c"}) },
+			current:      `[{"name":"a"},{"name":"b"}]`,
+			removeStatus: http.StatusOK,
+			wantRequests: []string{
+				"GET /repos/owner/repo/issues/1/labels",
+				"POST /repos/owner/repo/issues/1/labels",
+				"DELETE /repos/owner/repo/issues/1/labels/a",
+			},
+		},
+		{
+			name:    "replace labels with the same ones",
+			call:    func(c Client) error { return c.ReplaceLabels("owner", "repo", 1, []string{"b", "a"}) },
+			current: `[{"name":"a"},{"name":"b"}]`,
+			wantRequests: []string{
+				"GET /repos/owner/repo/issues/1/labels",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					fmt.Fprint(w, c.current)
+
+				case http.MethodDelete:
+					w.WriteHeader(c.removeStatus)
+					fmt.Fprint(w, "{}")
+
+				default:
+					fmt.Fprint(w, "[]")
+				}
+			})
+
+			err := c.call(s.client(t))
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Errorf("got requests %v, want %v", got, c.wantRequests)
+			}
+		})
+	}
+}
+
+func TestReplaceLabelsAddsOnlyMissingOnes(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `[{"name":"a"}]`)
+
+			return
+		}
+
+		fmt.Fprint(w, "[]")
+	})
+
+	if err := s.client(t).ReplaceLabels("owner", "repo", 1, []string{"a", "c", "b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := s.bodies[1], `["b","c"]`; got != want {
+		t.Errorf("got body %s, want %s", got, want)
+	}
+}
+
+func TestWholeCallTimeout(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []ClientOption
+		want time.Duration
+	}{
+		{name: "default", want: time.Minute},
+		{name: "long request timeout", opts: []ClientOption{WithTimeout(2 * time.Minute)}, want: 2 * time.Minute},
+		{name: "long retry window", opts: []ClientOption{WithRetryMaxElapsed(5 * time.Minute)}, want: 5 * time.Minute},
+		{name: "short retry window", opts: []ClientOption{WithRetryMaxElapsed(time.Second)}, want: time.Minute},
+		{
+			name: "explicit",
+			opts: []ClientOption{WithRetryMaxElapsed(5 * time.Minute), WithCallTimeout(10 * time.Second)},
+			want: 10 * time.Second,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cl, err := NewClientE(func() []byte { return nil }, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := cl.(client).timeout; got != c.want {
+				t.Errorf("got timeout %v, want %v", got, c.want)
+			}
+
+			ctx, cancel := cl.(client).newContext()
+			defer cancel()
+
+			if d, ok := ctx.Deadline(); !ok || time.Until(d) > c.want || time.Until(d) < c.want-time.Second {
+				t.Errorf("got deadline in %v, want %v", time.Until(d), c.want)
+			}
+		})
+	}
+}
+
+func TestWaitForReset(t *testing.T) {
+	reset := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		remaining int
+		now       time.Time
+		timeout   time.Duration
+		minWait   time.Duration
+		wantErr   error
+	}{
+		{name: "remaining above threshold", remaining: 100, now: reset.Add(-time.Hour)},
+		{name: "reset in the past", remaining: 0, now: reset.Add(time.Minute)},
+		{name: "reset in the future", remaining: 0, now: reset.Add(-50 * time.Millisecond), minWait: 50 * time.Millisecond},
+		{
+			name:      "ctx canceled while waiting",
+			remaining: 0,
+			now:       reset.Add(-time.Hour),
+			timeout:   20 * time.Millisecond,
+			minWait:   20 * time.Millisecond,
+			wantErr:   context.DeadlineExceeded,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.timeout)
+				defer cancel()
+			}
+
+			rate := sdk.Rate{Limit: 5000, Remaining: c.remaining, Reset: sdk.Timestamp{Time: reset}}
+			now := func() time.Time { return c.now }
+
+			start := time.Now()
+			err := waitForReset(ctx, rate, 100, now)
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("got error %v, want %v", err, c.wantErr)
+			}
+
+			if elapsed < c.minWait || elapsed > c.minWait+time.Second {
+				t.Errorf("waited %v, want about %v", elapsed, c.minWait)
+			}
+		})
+	}
+}
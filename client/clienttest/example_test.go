@@ -0,0 +1,47 @@
+package clienttest_test
+
+import (
+	"fmt"
+
+	"github.com/opensourceways/robot-github-lib/client"
+	"github.com/opensourceways/robot-github-lib/client/clienttest"
+)
+
+func ExampleStaticTokenGenerator() {
+	gen := clienttest.StaticTokenGenerator("secret")
+
+	payload := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+
+	for _, algorithm := range []string{client.AlgorithmSHA1, client.AlgorithmSHA256} {
+		sig := client.PayloadSignatureWith(algorithm, payload, []byte("secret"))
+
+		fmt.Println(algorithm, client.ValidatePayload(payload, sig, gen))
+	}
+
+	sig := client.PayloadSignatureWith(client.AlgorithmSHA256, payload, []byte("other"))
+	fmt.Println("other secret", client.ValidatePayload(payload, sig, gen))
+
+	// Output:
+	// sha1 true
+	// sha256 true
+	// other secret false
+}
+
+func ExampleMapTokenGenerator() {
+	gen := clienttest.MapTokenGenerator(map[string][]clienttest.Secret{
+		"owner/repo": {{Value: "repo-secret"}},
+		"*":          {{Value: "global-secret"}},
+	})
+
+	repo := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+	other := []byte(`{"repository":{"full_name":"owner/other"}}`)
+
+	fmt.Println(client.ValidatePayload(repo, client.PayloadSignature256(repo, []byte("repo-secret")), gen))
+	fmt.Println(client.ValidatePayload(repo, client.PayloadSignature256(repo, []byte("global-secret")), gen))
+	fmt.Println(client.ValidatePayload(other, client.PayloadSignature256(other, []byte("global-secret")), gen))
+
+	// Output:
+	// true
+	// false
+	// true
+}
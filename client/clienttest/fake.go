@@ -0,0 +1,161 @@
+package clienttest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opensourceways/robot-github-lib/client"
+)
+
+// FakeClient is an in-memory client.Client for testing the bots. It records the
+// comments and labels of issues and pull requests. The other methods are those
+// of the embedded Client, so a bot can set it to its own mock, or override them
+// by embedding FakeClient. Calling them panics if the embedded Client is nil.
+//
+//	c := clienttest.NewFakeClient("robot")
+//	_ = bot.handle(c, event)
+//	c.Comments(client.PRInfo{Org: "owner", Repo: "repo", Number: 1})
+type FakeClient struct {
+	client.Client
+
+	lock sync.Mutex
+
+	bot      string
+	comments map[string][]string
+	labels   map[string]map[string]bool
+}
+
+// NewFakeClient returns a FakeClient whose authenticated user is bot.
+func NewFakeClient(bot string) *FakeClient {
+	return &FakeClient{
+		bot:      bot,
+		comments: map[string][]string{},
+		labels:   map[string]map[string]bool{},
+	}
+}
+
+// Comments returns the comments created on the issue or pull request in order.
+func (c *FakeClient) Comments(pr client.PRInfo) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]string(nil), c.comments[pr.String()]...)
+}
+
+// Labels returns the labels of the issue or pull request in lexical order.
+func (c *FakeClient) Labels(pr client.PRInfo) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.labelsOf(pr)
+}
+
+// WithCallOptions returns the FakeClient itself, since it makes no request.
+func (c *FakeClient) WithCallOptions(opts ...client.CallOption) client.Client {
+	return c
+}
+
+func (c *FakeClient) GetBot() (string, error) {
+	return c.bot, nil
+}
+
+func (c *FakeClient) CreateComment(org, repo string, number int, comment string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	k := client.PRInfo{Org: org, Repo: repo, Number: number}.String()
+	c.comments[k] = append(c.comments[k], comment)
+
+	return nil
+}
+
+func (c *FakeClient) CreatePRComment(pr client.PRInfo, comment string) error {
+	return c.CreateComment(pr.Org, pr.Repo, pr.Number, comment)
+}
+
+func (c *FakeClient) CreateIssueComment(is client.PRInfo, comment string) error {
+	return c.CreateComment(is.Org, is.Repo, is.Number, comment)
+}
+
+func (c *FakeClient) AddLabel(org, repo string, number int, label string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	k := client.PRInfo{Org: org, Repo: repo, Number: number}.String()
+	if c.labels[k] == nil {
+		c.labels[k] = map[string]bool{}
+	}
+	c.labels[k][label] = true
+
+	return nil
+}
+
+func (c *FakeClient) RemoveLabel(org, repo string, number int, label string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	k := client.PRInfo{Org: org, Repo: repo, Number: number}.String()
+	for l := range c.labels[k] {
+		if strings.EqualFold(l, label) {
+			delete(c.labels[k], l)
+		}
+	}
+
+	return nil
+}
+
+func (c *FakeClient) ReplaceLabels(org, repo string, number int, labels []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	m := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		m[l] = true
+	}
+	c.labels[client.PRInfo{Org: org, Repo: repo, Number: number}.String()] = m
+
+	return nil
+}
+
+func (c *FakeClient) AddPRLabel(pr client.PRInfo, label string) error {
+	return c.AddLabel(pr.Org, pr.Repo, pr.Number, label)
+}
+
+func (c *FakeClient) RemovePRLabel(pr client.PRInfo, label string) error {
+	return c.RemoveLabel(pr.Org, pr.Repo, pr.Number, label)
+}
+
+func (c *FakeClient) GetPRLabels(pr client.PRInfo) ([]string, error) {
+	return c.Labels(pr), nil
+}
+
+func (c *FakeClient) AddIssueLabel(is client.PRInfo, labels []string) error {
+	for _, l := range labels {
+		_ = c.AddLabel(is.Org, is.Repo, is.Number, l)
+	}
+
+	return nil
+}
+
+func (c *FakeClient) RemoveIssueLabel(is client.PRInfo, label string) error {
+	return c.RemoveLabel(is.Org, is.Repo, is.Number, label)
+}
+
+func (c *FakeClient) GetIssueLabels(is client.PRInfo) ([]string, error) {
+	return c.Labels(is), nil
+}
+
+func (c *FakeClient) labelsOf(pr client.PRInfo) []string {
+	m := c.labels[pr.String()]
+
+	r := make([]string, 0, len(m))
+	for l := range m {
+		r = append(r, l)
+	}
+	sort.Strings(r)
+
+	return r
+}
+
+var _ client.Client = (*FakeClient)(nil)
@@ -0,0 +1,44 @@
+// Package clienttest provides the helpers to test the handlers against the
+// real webhook validation of package client. For example:
+//
+//	gen := clienttest.MapTokenGenerator(map[string][]clienttest.Secret{
+//		"owner/repo": {{Value: "secret"}},
+//	})
+//
+//	payload := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+//	sig := client.PayloadSignature256(payload, []byte("secret"))
+//
+//	ok := client.ValidatePayload(payload, sig, gen) // true
+package clienttest
+
+import (
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Secret is a hmac token of the hierarchical secret file.
+type Secret struct {
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StaticTokenGenerator returns the token generator of the legacy single token format.
+func StaticTokenGenerator(secret string) func() []byte {
+	return func() []byte {
+		return []byte(secret)
+	}
+}
+
+// MapTokenGenerator returns the token generator of the hierarchical format whose
+// keys are repo, org, or "*". It panics if the secrets can't be marshalled.
+func MapTokenGenerator(secrets map[string][]Secret) func() []byte {
+	v, err := yaml.Marshal(secrets)
+	if err != nil {
+		panic(err)
+	}
+
+	return func() []byte {
+		return v
+	}
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersRule is a line of CODEOWNERS file.
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// MatchCodeOwners returns the owners of each changed file by the rules of the
+// CODEOWNERS file. The owners are the users, teams such as "@org/team" or the
+// emails. Like github, the last rule matching a file wins, and the file is not
+// in the result if the last matching rule has no owners or no rule matches it.
+// The negation "!" and the character range "[]" are not supported by github,
+// so the rule with them is an error.
+func MatchCodeOwners(codeowners []byte, changedFiles []string) (map[string][]string, error) {
+	rules, err := parseCodeOwners(codeowners)
+	if err != nil {
+		return nil, err
+	}
+
+	r := map[string][]string{}
+	for _, f := range changedFiles {
+		p := strings.TrimPrefix(f, "/")
+
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].pattern.MatchString(p) {
+				if len(rules[i].owners) > 0 {
+					r[f] = rules[i].owners
+				}
+
+				break
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func parseCodeOwners(codeowners []byte) ([]codeOwnersRule, error) {
+	var rules []codeOwnersRule
+
+	s := bufio.NewScanner(bytes.NewReader(codeowners))
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		pattern, err := compileCodeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule at line %d: %v", n, err)
+		}
+
+		rule := codeOwnersRule{pattern: pattern}
+		for _, v := range fields[1:] {
+			if strings.HasPrefix(v, "#") {
+				break
+			}
+
+			rule.owners = append(rule.owners, v)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// compileCodeOwnersPattern converts the gitignore style pattern to the regexp
+// matching the path relative to the root of repo.
+//   - The pattern starting with or containing a "/" is relative to the root,
+//     otherwise it matches at any depth.
+//   - The pattern ending with a "/" matches the directory only.
+//   - The pattern matching a directory matches all the files in it, except that
+//     a trailing "*" only matches the direct files, such as "docs/*".
+//   - "*" and "?" don't match "/", while "**" matches the directories at any depth.
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return nil, fmt.Errorf("negation pattern %s is not supported", pattern)
+	}
+
+	if strings.ContainsAny(pattern, "[]") {
+		return nil, fmt.Errorf("character range in pattern %s is not supported", pattern)
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %s", pattern)
+	}
+
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(p, "/")
+
+	b := strings.Builder{}
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	segs := strings.Split(p, "/")
+	last := segs[len(segs)-1]
+
+	for i, seg := range segs {
+		if seg == "**" {
+			if i == len(segs)-1 {
+				b.WriteString(".*")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+
+			continue
+		}
+
+		for _, c := range seg {
+			switch c {
+			case '*':
+				b.WriteString("[^/]*")
+
+			case '?':
+				b.WriteString("[^/]")
+
+			default:
+				b.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+
+		if i < len(segs)-1 {
+			b.WriteString("/")
+		}
+	}
+
+	switch {
+	case last == "**":
+		// It has matched all the files in the directory.
+
+	case dirOnly:
+		b.WriteString("/.*")
+
+	case !strings.Contains(last, "*"):
+		b.WriteString("(?:/.*)?")
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
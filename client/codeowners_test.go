@@ -0,0 +1,106 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchCodeOwners(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   string
+		files   []string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name:  "last match wins",
+			rules: "* @global\n*.go @gopher\n",
+			files: []string{"README.md", "pkg/a.go"},
+			want:  map[string][]string{"README.md": {"@global"}, "pkg/a.go": {"@gopher"}},
+		},
+		{
+			name:  "directory glob at any depth",
+			rules: "docs/ @writer\n",
+			files: []string{"docs/a.md", "docs/sub/b.md", "src/docs/c.md", "docs"},
+			want: map[string][]string{
+				"docs/a.md": {"@writer"}, "docs/sub/b.md": {"@writer"}, "src/docs/c.md": {"@writer"},
+			},
+		},
+		{
+			name:  "unanchored name",
+			rules: "build @ci\n",
+			files: []string{"build/x", "app/build/y", "builder/z"},
+			want:  map[string][]string{"build/x": {"@ci"}, "app/build/y": {"@ci"}},
+		},
+		{
+			name:  "anchored directory",
+			rules: "/build/ @ci\n",
+			files: []string{"build/x", "app/build/y"},
+			want:  map[string][]string{"build/x": {"@ci"}},
+		},
+		{
+			name:  "trailing star matches the direct files only",
+			rules: "docs/* @writer\n",
+			files: []string{"docs/a.md", "docs/sub/b.md"},
+			want:  map[string][]string{"docs/a.md": {"@writer"}},
+		},
+		{
+			name:  "double star",
+			rules: "apps/**/test/ @qa\nlib/** @lib\n",
+			files: []string{"apps/test/a", "apps/x/y/test/b", "apps/x/c", "lib/a/b/c.go"},
+			want:  map[string][]string{"apps/test/a": {"@qa"}, "apps/x/y/test/b": {"@qa"}, "lib/a/b/c.go": {"@lib"}},
+		},
+		{
+			name:  "rule without owners unowns the files",
+			rules: "* @global\n/vendor/\n",
+			files: []string{"vendor/a.go", "main.go"},
+			want:  map[string][]string{"main.go": {"@global"}},
+		},
+		{
+			name:  "comments and multiple owners",
+			rules: "# owners\n*.js @a @org/team user@example.com # trailing\n",
+			files: []string{"web/app.js"},
+			want:  map[string][]string{"web/app.js": {"@a", "@org/team", "user@example.com"}},
+		},
+		{
+			name:  "leading slash of file",
+			rules: "/README.md @doc\n",
+			files: []string{"/README.md"},
+			want:  map[string][]string{"/README.md": {"@doc"}},
+		},
+		{
+			name:    "negation is not supported",
+			rules:   "* @global\n!*.md\n",
+			files:   []string{"README.md"},
+			wantErr: true,
+		},
+		{
+			name:    "character range is not supported",
+			rules:   "*.[ch] @c\n",
+			files:   []string{"a.c"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := MatchCodeOwners([]byte(c.rules), c.files)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
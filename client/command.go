@@ -0,0 +1,53 @@
+package client
+
+import (
+	"regexp"
+	"strings"
+)
+
+var commandRegex = regexp.MustCompile(`^/([A-Za-z][\w-]*)(?:\s+(.*))?$`)
+
+// Command is a slash command in the comment, such as "/hold cancel".
+type Command struct {
+	// Name is the name of command in lower case without the slash, such as "hold".
+	Name string
+	// Args are the arguments separated by spaces, such as ["cancel"].
+	Args []string
+	// Line is the line of comment where the command is.
+	Line string
+}
+
+// ParseCommands returns the commands in the comment body. Each command must
+// be at the beginning of a line. The lines which are quoted or in the code
+// blocks are ignored, so that the quoted commands will not be triggered again.
+func ParseCommands(body string) []Command {
+	var r []Command
+
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode || strings.HasPrefix(line, ">") {
+			continue
+		}
+
+		m := commandRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		r = append(r, Command{
+			Name: strings.ToLower(m[1]),
+			Args: strings.Fields(m[2]),
+			Line: line,
+		})
+	}
+
+	return r
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// DeleteStaleBotComments deletes the comments of issue or pull request which are
+// authored by botLogin and contain the marker, for example a hidden html comment
+// such as "<!-- ci-status -->". It returns the number of comments deleted, which
+// is also meaningful when it fails halfway, for example the timeout of call
+// expires. Both botLogin and marker are required, otherwise the comments of
+// others or all the comments of bot would be deleted.
+func (cl client) DeleteStaleBotComments(org, repo string, number int, botLogin, marker string) (int, error) {
+	if botLogin == "" || marker == "" {
+		return 0, errors.New("missing bot login or marker of comments to delete")
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	comments, err := cl.listComments(ctx, org, repo, number)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, c := range comments {
+		if c.GetUser().GetLogin() != botLogin || !strings.Contains(c.GetBody(), marker) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		if _, err := cl.c.Issues.DeleteComment(ctx, org, repo, c.GetID()); err != nil {
+			return n, fmt.Errorf("failed to delete comment %d of %s: %w", c.GetID(), PRInfo{org, repo, number}, err)
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// listComments returns all the comments of issue or pull request.
+func (cl client) listComments(ctx context.Context, org, repo string, number int) ([]*sdk.IssueComment, error) {
+	var comments []*sdk.IssueComment
+
+	opt := &sdk.IssueListCommentsOptions{ListOptions: sdk.ListOptions{Page: 1, PerPage: 100}}
+	for {
+		v, resp, err := cl.c.Issues.ListComments(ctx, org, repo, number, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		comments = append(comments, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return comments, nil
+}
+
+// UpsertComment keeps a single sticky comment containing the marker on the issue
+// or pull request. The comment of the authenticated user containing the marker
+// is edited in place if it exists, otherwise a new comment is created. Different
+// markers let multiple bots or features coexist on the same pull request.
+// The marker is required, otherwise any comment of the user would be overwritten.
+func (cl client) UpsertComment(org, repo string, number int, marker, body string) error {
+	if marker == "" {
+		return errors.New("missing marker of sticky comment")
+	}
+
+	login, err := cl.authenticatedLogin()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	comments, err := cl.listComments(ctx, org, repo, number)
+	if err != nil {
+		return err
+	}
+
+	ic := &sdk.IssueComment{Body: sdk.String(body)}
+
+	for _, c := range comments {
+		if c.GetUser().GetLogin() != login || !strings.Contains(c.GetBody(), marker) {
+			continue
+		}
+
+		if c.GetBody() == body {
+			return nil
+		}
+
+		if _, _, err := cl.c.Issues.EditComment(ctx, org, repo, c.GetID(), ic); err != nil {
+			return fmt.Errorf("failed to edit comment %d of %s: %w", c.GetID(), PRInfo{org, repo, number}, err)
+		}
+
+		return nil
+	}
+
+	if _, _, err := cl.c.Issues.CreateComment(ctx, org, repo, number, ic); err != nil {
+		return fmt.Errorf("failed to create comment on %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// authenticatedLogin returns the login of the authenticated user. It is the
+// login of bot, "<app slug>[bot]", if the client authenticates as the
+// installation of GitHub App, see WithAppAuth, because /user is not accessible
+// with the installation token. The result is cached for a minute.
+func (cl client) authenticatedLogin() (string, error) {
+	const key = "authenticated-login"
+	if v, ok := cl.cache.get(key); ok {
+		return v.(string), nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var login string
+	if cl.app != nil {
+		a, _, err := cl.app.Apps.Get(ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to get the authenticated GitHub App: %w", err)
+		}
+
+		login = a.GetSlug() + "[bot]"
+	} else {
+		u, _, err := cl.c.Users.Get(ctx, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to get the authenticated user: %w", err)
+		}
+
+		login = u.GetLogin()
+	}
+
+	cl.cache.set(key, login)
+
+	return login, nil
+}
+
+// GetCommentNodeID returns the node id of the comment of issue or pull request,
+// which is required by the GraphQL API, for example to minimize the comment.
+func (cl client) GetCommentNodeID(org, repo string, commentID int64) (string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	c, _, err := cl.c.Issues.GetComment(ctx, org, repo, commentID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get comment %d of %s/%s: %w", commentID, org, repo, err)
+	}
+
+	return c.GetNodeID(), nil
+}
+
+// GetReviewCommentNodeID is the same as GetCommentNodeID except that it is for
+// the review comment of pull request.
+func (cl client) GetReviewCommentNodeID(org, repo string, commentID int64) (string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	c, _, err := cl.c.PullRequests.GetComment(ctx, org, repo, commentID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get review comment %d of %s/%s: %w", commentID, org, repo, err)
+	}
+
+	return c.GetNodeID(), nil
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testComments = `[
+	{"id":1,"body":"<!-- other --> hi","user":{"login":"bot"}},
+	{"id":2,"body":"<!-- sticky --> old","user":{"login":"someone"}},
+	{"id":3,"body":"<!-- sticky --> old","user":{"login":"bot"}}
+]`
+
+// newCommentsServer returns a testServer of which the authenticated user is
+// bot and the pull request has the testComments.
+func newCommentsServer(t *testing.T) *testServer {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/user":
+			fmt.Fprint(w, `{"login":"bot"}`)
+
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, testComments)
+
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+}
+
+func TestUpsertComment(t *testing.T) {
+	cases := []struct {
+		name         string
+		marker       string
+		body         string
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			name:   "update",
+			marker: "<!-- sticky -->",
+			body:   "<!-- sticky --> new",
+			wantRequests: []string{
+				"GET /user",
+				"GET /repos/owner/repo/issues/1/comments",
+				"PATCH /repos/owner/repo/issues/comments/3",
+			},
+		},
+		{
+			name:   "unchanged",
+			marker: "<!-- sticky -->",
+			body:   "<!-- sticky --> old",
+			wantRequests: []string{
+				"GET /user",
+				"GET /repos/owner/repo/issues/1/comments",
+			},
+		},
+		{
+			name:   "create",
+			marker: "<!-- new -->",
+			body:   "<!-- new --> hello",
+			wantRequests: []string{
+				"GET /user",
+				"GET /repos/owner/repo/issues/1/comments",
+				"POST /repos/owner/repo/issues/1/comments",
+			},
+		},
+		{
+			name:    "empty marker",
+			body:    "hello",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newCommentsServer(t)
+
+			err := s.client(t).UpsertComment("owner", "repo", 1, c.marker, c.body)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Errorf("got requests %v, want %v", got, c.wantRequests)
+			}
+		})
+	}
+}
+
+// testAppKey returns a PEM encoded private key of GitHub App.
+func testAppKey(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestUpsertCommentWithAppAuth(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v3")
+
+		switch {
+		case path == "/user":
+			// The installation token can't access the authenticated user.
+			w.WriteHeader(http.StatusForbidden)
+
+		case path == "/app":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"slug":"robot"}`)
+
+		case path == "/app/installations/1/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"token":"installation-token","expires_at":"2100-01-01T00:00:00Z"}`)
+
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `[
+				{"id":1,"body":"<!-- sticky --> old","user":{"login":"robot"}},
+				{"id":2,"body":"<!-- sticky --> old","user":{"login":"robot[bot]"}}
+			]`)
+
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	c := s.client(t, WithAppAuth(10, testAppKey(t), 1))
+	if err := c.UpsertComment("owner", "repo", 1, "<!-- sticky -->", "<!-- sticky --> new"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"GET /app",
+		"POST /app/installations/1/access_tokens",
+		"GET /repos/owner/repo/issues/1/comments",
+		"PATCH /repos/owner/repo/issues/comments/2",
+	}
+	if got := s.received(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+}
+
+func TestDeleteStaleBotComments(t *testing.T) {
+	cases := []struct {
+		name         string
+		botLogin     string
+		marker       string
+		want         int
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			name:     "delete the marked comments of bot",
+			botLogin: "bot",
+			marker:   "<!-- sticky -->",
+			want:     1,
+			wantRequests: []string{
+				"GET /repos/owner/repo/issues/1/comments",
+				"DELETE /repos/owner/repo/issues/comments/3",
+			},
+		},
+		{
+			name:     "nothing to delete",
+			botLogin: "bot",
+			marker:   "<!-- none -->",
+			wantRequests: []string{
+				"GET /repos/owner/repo/issues/1/comments",
+			},
+		},
+		{name: "empty marker", botLogin: "bot", wantErr: true},
+		{name: "empty bot login", marker: "<!-- sticky -->", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newCommentsServer(t)
+
+			n, err := s.client(t).DeleteStaleBotComments("owner", "repo", 1, c.botLogin, c.marker)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if n != c.want {
+				t.Errorf("got %d deleted, want %d", n, c.want)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Errorf("got requests %v, want %v", got, c.wantRequests)
+			}
+		})
+	}
+}
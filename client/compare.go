@@ -0,0 +1,49 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// CompareCommits returns the comparison between base and head. The commits of
+// comparison are paginated, so all the pages are fetched and aggregated, while
+// the files of the first page are kept. The sdk doesn't support the pagination
+// of it yet.
+func (cl client) CompareCommits(org, repo, base, head string) (*sdk.CommitsComparison, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var r *sdk.CommitsComparison
+
+	u := fmt.Sprintf("repos/%s/%s/compare/%s...%s", org, repo, url.QueryEscape(base), url.QueryEscape(head))
+	for page := 1; ; {
+		req, err := cl.c.NewRequest("GET", fmt.Sprintf("%s?page=%d&per_page=100", u, page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		v := new(sdk.CommitsComparison)
+		resp, err := cl.c.Do(ctx, req, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s...%s of %s/%s: %w", base, head, org, repo, err)
+		}
+
+		if r == nil {
+			r = v
+		} else {
+			r.Commits = append(r.Commits, v.Commits...)
+		}
+
+		if page, err = nextPage(resp); err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+	}
+
+	return r, nil
+}
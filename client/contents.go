@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+var (
+	// ErrFileNotFound is returned when the file doesn't exist at the ref.
+	ErrFileNotFound = errors.New("file not found")
+
+	// ErrRefNotFound is returned when the ref doesn't exist.
+	ErrRefNotFound = errors.New("ref not found")
+)
+
+// GetFileContent returns the content of file at the ref. The content of file
+// larger than 1MB is fetched by the blob API, because the contents API doesn't
+// return it.
+func (cl client) GetFileContent(org, repo, path, ref string) ([]byte, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	fc, _, r, err := cl.c.Repositories.GetContents(ctx, org, repo, path, &sdk.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if r == nil || r.StatusCode != http.StatusNotFound {
+			return nil, fmt.Errorf("failed to get content of %s/%s/%s@%s: %w", org, repo, path, ref, err)
+		}
+
+		// Tell whether the file or the ref is missing.
+		if _, r1, err1 := cl.c.Repositories.GetCommitSHA1(ctx, org, repo, ref, ""); err1 != nil &&
+			r1 != nil && (r1.StatusCode == http.StatusNotFound || r1.StatusCode == http.StatusUnprocessableEntity) {
+			return nil, fmt.Errorf("%w: %s/%s@%s", ErrRefNotFound, org, repo, ref)
+		}
+
+		return nil, fmt.Errorf("%w: %s/%s/%s@%s", ErrFileNotFound, org, repo, path, ref)
+	}
+
+	if fc == nil {
+		return nil, fmt.Errorf("%s/%s/%s@%s is a directory", org, repo, path, ref)
+	}
+
+	// The content is empty with encoding of "none" for the file larger than 1MB.
+	if fc.GetEncoding() == "none" || (fc.Content == nil && fc.GetSize() > 0) {
+		b, _, err := cl.c.Git.GetBlobRaw(ctx, org, repo, fc.GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blob of %s/%s/%s@%s: %w", org, repo, path, ref, err)
+		}
+
+		return b, nil
+	}
+
+	content, err := fc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content of %s/%s/%s@%s: %w", org, repo, path, ref, err)
+	}
+
+	return []byte(content), nil
+}
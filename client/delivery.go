@@ -0,0 +1,105 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDeliveryTTL is the default time to remember a delivery.
+	DefaultDeliveryTTL = time.Hour
+
+	defaultDeliveryStoreSize = 10000
+)
+
+// DeliveryStore remembers the deliveries of webhook identified by the
+// X-GitHub-Delivery header, so that the redelivered ones can be skipped.
+// It can be backed by a shared storage such as Redis for multiple replicas.
+type DeliveryStore interface {
+	// MarkSeen records the delivery and tells whether it was seen recently.
+	// It must be atomic if the store is shared.
+	MarkSeen(id string) (bool, error)
+
+	// Forget removes the delivery, so that its redelivery is processed again.
+	// It is called when processing the delivery fails.
+	Forget(id string) error
+}
+
+type delivery struct {
+	id       string
+	expireAt time.Time
+}
+
+// memoryDeliveryStore is a DeliveryStore which keeps the deliveries in an
+// LRU list of limited size, and each delivery expires after the ttl.
+type memoryDeliveryStore struct {
+	lock sync.Mutex
+
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+// NewMemoryDeliveryStore returns an in-memory DeliveryStore which remembers at most
+// size deliveries for ttl. The ttl is DefaultDeliveryTTL if it is not positive,
+// and the size is 10000 if it is not positive.
+func NewMemoryDeliveryStore(size int, ttl time.Duration) DeliveryStore {
+	if size <= 0 {
+		size = defaultDeliveryStoreSize
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultDeliveryTTL
+	}
+
+	return &memoryDeliveryStore{
+		size:  size,
+		ttl:   ttl,
+		items: map[string]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+func (s *memoryDeliveryStore) MarkSeen(id string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	if e, ok := s.items[id]; ok {
+		d := e.Value.(*delivery)
+		if now.Before(d.expireAt) {
+			s.lru.MoveToFront(e)
+
+			return true, nil
+		}
+
+		s.remove(e)
+	}
+
+	s.items[id] = s.lru.PushFront(&delivery{id: id, expireAt: now.Add(s.ttl)})
+
+	for s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+
+	return false, nil
+}
+
+func (s *memoryDeliveryStore) Forget(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.items[id]; ok {
+		s.remove(e)
+	}
+
+	return nil
+}
+
+func (s *memoryDeliveryStore) remove(e *list.Element) {
+	s.lru.Remove(e)
+	delete(s.items, e.Value.(*delivery).id)
+}
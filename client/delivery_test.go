@@ -0,0 +1,69 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryDeliveryStore(t *testing.T) {
+	cases := []struct {
+		name  string
+		size  int
+		ttl   time.Duration
+		wait  time.Duration
+		ids   []string
+		check string
+		seen  bool
+	}{
+		{name: "seen recently", size: 2, ttl: time.Hour, ids: []string{"a"}, check: "a", seen: true},
+		{name: "not seen", size: 2, ttl: time.Hour, ids: []string{"a"}, check: "b"},
+		{name: "evicted by the size", size: 2, ttl: time.Hour, ids: []string{"a", "b", "c"}, check: "a"},
+		{name: "used recently is kept", size: 2, ttl: time.Hour, ids: []string{"a", "b", "a", "c"}, check: "a", seen: true},
+		{name: "expired", size: 2, ttl: 10 * time.Millisecond, wait: 20 * time.Millisecond, ids: []string{"a"}, check: "a"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewMemoryDeliveryStore(c.size, c.ttl)
+
+			for _, id := range c.ids {
+				if _, err := s.MarkSeen(id); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			time.Sleep(c.wait)
+
+			seen, err := s.MarkSeen(c.check)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if seen != c.seen {
+				t.Errorf("got seen %t, want %t", seen, c.seen)
+			}
+		})
+	}
+}
+
+func TestMemoryDeliveryStoreForget(t *testing.T) {
+	s := NewMemoryDeliveryStore(0, 0)
+
+	for _, want := range []bool{false, true} {
+		if seen, _ := s.MarkSeen("a"); seen != want {
+			t.Fatalf("got seen %t, want %t", seen, want)
+		}
+	}
+
+	if err := s.Forget("a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if seen, _ := s.MarkSeen("a"); seen {
+		t.Error("the forgotten delivery is seen")
+	}
+
+	if err := s.Forget("unknown"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
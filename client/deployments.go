@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+var (
+	// ErrDeploymentAutoMerged is returned when github merges the default branch
+	// into the ref instead of creating the deployment. The deployment can be
+	// created again for the new head of ref.
+	ErrDeploymentAutoMerged = errors.New("deployment not created because the default branch is merged into the ref")
+
+	// ErrDeploymentConflict is returned when the default branch can't be merged
+	// into the ref automatically, or the required contexts are not successful.
+	ErrDeploymentConflict = errors.New("deployment not created because of conflict")
+)
+
+// CreateDeployment creates the deployment of the ref. The environment and
+// transient environment are set by the fields of req. By default, github merges
+// the default branch into the ref if it is behind, in which case the deployment
+// is not created and ErrDeploymentAutoMerged is returned. Set AutoMerge to false
+// to disable it.
+func (cl client) CreateDeployment(org, repo string, req *sdk.DeploymentRequest) (*sdk.Deployment, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	d, r, err := cl.c.Repositories.CreateDeployment(ctx, org, repo, req)
+	if err == nil {
+		return d, nil
+	}
+
+	var accepted *sdk.AcceptedError
+	switch {
+	case errors.As(err, &accepted):
+		var v struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(accepted.Raw, &v)
+
+		return nil, fmt.Errorf("failed to create deployment of %s in %s/%s: %w: %s", req.GetRef(), org, repo, ErrDeploymentAutoMerged, v.Message)
+
+	case r != nil && r.StatusCode == http.StatusConflict:
+		return nil, fmt.Errorf("failed to create deployment of %s in %s/%s: %w: %v", req.GetRef(), org, repo, ErrDeploymentConflict, err)
+	}
+
+	return nil, fmt.Errorf("failed to create deployment of %s in %s/%s: %w", req.GetRef(), org, repo, err)
+}
+
+// CreateDeploymentStatus creates the status of deployment, such as "in_progress"
+// and "success".
+func (cl client) CreateDeploymentStatus(
+	org, repo string, deploymentID int64, req *sdk.DeploymentStatusRequest,
+) (*sdk.DeploymentStatus, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, _, err := cl.c.Repositories.CreateDeploymentStatus(ctx, org, repo, deploymentID, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status of deployment %d in %s/%s: %w", deploymentID, org, repo, err)
+	}
+
+	return v, nil
+}
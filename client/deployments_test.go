@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+func TestCreateDeployment(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		response string
+		wantID   int64
+		wantErr  error
+	}{
+		{
+			name:     "created",
+			status:   http.StatusCreated,
+			response: `{"id":42,"ref":"topic","environment":"staging"}`,
+			wantID:   42,
+		},
+		{
+			name:     "default branch auto merged",
+			status:   http.StatusAccepted,
+			response: `{"message":"Auto-merged main into topic on deployment."}`,
+			wantErr:  ErrDeploymentAutoMerged,
+		},
+		{
+			name:     "merge conflict",
+			status:   http.StatusConflict,
+			response: `{"message":"Conflict merging main into topic."}`,
+			wantErr:  ErrDeploymentConflict,
+		},
+		{
+			name:     "required contexts failed",
+			status:   http.StatusConflict,
+			response: `{"message":"Conflict: Commit status checks failed for topic.","errors":[{"contexts":[{"context":"ci","state":"failure"}]}]}`,
+			wantErr:  ErrDeploymentConflict,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(c.status)
+				fmt.Fprint(w, c.response)
+			})
+
+			d, err := s.client(t).CreateDeployment("owner", "repo", &sdk.DeploymentRequest{
+				Ref:                   sdk.String("topic"),
+				Environment:           sdk.String("staging"),
+				TransientEnvironment:  sdk.Bool(true),
+				ProductionEnvironment: sdk.Bool(false),
+			})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("got error %v, want %v", err, c.wantErr)
+				}
+
+				if d != nil {
+					t.Errorf("got deployment %v, want none", d)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if d.GetID() != c.wantID {
+				t.Errorf("got deployment %d, want %d", d.GetID(), c.wantID)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(s.bodies[0]), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body["environment"] != "staging" || body["transient_environment"] != true || body["production_environment"] != false {
+				t.Errorf("got body %s", s.bodies[0])
+			}
+		})
+	}
+}
+
+func TestCreateDeploymentStatusTransitions(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	cli := s.client(t)
+
+	states := []string{"queued", "in_progress", "success", "inactive"}
+	for _, state := range states {
+		if _, err := cli.CreateDeploymentStatus("owner", "repo", 42, &sdk.DeploymentStatusRequest{
+			State:       sdk.String(state),
+			Environment: sdk.String("staging"),
+		}); err != nil {
+			t.Fatalf("unexpected error of %s: %v", state, err)
+		}
+	}
+
+	for i, b := range s.bodies {
+		var body struct {
+			State       string `json:"state"`
+			Environment string `json:"environment"`
+		}
+		if err := json.Unmarshal([]byte(b), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body.State != states[i] || body.Environment != "staging" {
+			t.Errorf("got body %s of status %d, want state %s", b, i, states[i])
+		}
+
+		if got, want := s.received()[i], "POST /repos/owner/repo/deployments/42/statuses"; got != want {
+			t.Errorf("got request %s, want %s", got, want)
+		}
+	}
+}
+
+func TestCreateDeploymentStatusFailure(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	})
+
+	_, err := s.client(t).CreateDeploymentStatus("owner", "repo", 42, &sdk.DeploymentStatusRequest{State: sdk.String("success")})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if e, ok := AsGitHubError(err); !ok || !e.IsNotFound() {
+		t.Errorf("got error %v, want the not found error of github", err)
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// WithDryRun makes the client log the mutating requests instead of sending them
+// to github, and respond them with a synthesized success. The read requests are
+// sent normally, including the GraphQL queries, so that a new bot can be run
+// against production to see what it would do. The results of mutating methods
+// are empty in dry run.
+func WithDryRun(enabled bool) ClientOption {
+	return func(o *clientOptions) {
+		o.dryRun = enabled
+	}
+}
+
+// dryRunTransport intercepts the mutating requests.
+type dryRunTransport struct {
+	base http.RoundTripper
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isMutating(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	defaultLogger.Info("dry run", LogFields{
+		"method": req.Method,
+		"url":    req.URL.String(),
+	})
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	// The null body decodes to the empty result of any type, either an object
+	// or an array such as the labels added.
+	status := http.StatusOK
+	body := "null"
+	if req.Method == http.MethodDelete {
+		status = http.StatusNoContent
+		body = ""
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// isMutating tells whether the request changes anything on github. The GraphQL
+// request is mutating only if it is a mutation, which is read from the body.
+func isMutating(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	if !strings.HasSuffix(req.URL.Path, "/graphql") || req.GetBody == nil {
+		return true
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return true
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return true
+	}
+
+	var v struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return true
+	}
+
+	return bytes.HasPrefix(bytes.TrimSpace([]byte(v.Query)), []byte("mutation"))
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDryRunMakesNoMutatingRequests(t *testing.T) {
+	pr := PRInfo{Org: "owner", Repo: "repo", Number: 1}
+
+	cases := []struct {
+		name string
+		call func(Client) error
+	}{
+		{name: "create comment", call: func(c Client) error { return c.CreateComment("owner", "repo", 1, "hi") }},
+		{name: "add label", call: func(c Client) error { return c.AddLabel("owner", "repo", 1, "bug") }},
+		{name: "remove label", call: func(c Client) error { return c.RemoveLabel("owner", "repo", 1, "bug") }},
+		{name: "close pull request", call: func(c Client) error { return c.ClosePR(pr) }},
+		{name: "close issue", call: func(c Client) error { return c.CloseIssue(pr) }},
+		{name: "merge", call: func(c Client) error { return c.Merge("owner", "repo", 1, MergeMethodSquash, "") }},
+		{name: "reaction", call: func(c Client) error { return c.CreateCommentReaction("owner", "repo", 1, "+1") }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "{}")
+			})
+
+			if err := c.call(s.client(t, WithDryRun(true))); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := s.received(); len(got) != 0 {
+				t.Errorf("got requests %v in dry run", got)
+			}
+		})
+	}
+}
+
+func TestDryRunSendsReadRequests(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number":1}`)
+	})
+
+	if _, err := s.client(t, WithDryRun(true)).GetSinglePR("owner", "repo", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := s.received(), []string{"GET /repos/owner/repo/pulls/1"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+}
+
+func TestDryRunMakesNoGraphQLMutations(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+
+	c, err := NewGraphQLClientE(
+		func() []byte { return []byte("token") },
+		WithBaseURL(s.URL+"/", s.URL+"/"), WithDryRun(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.MinimizeComment("node-id", "spam"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := s.received(); len(got) != 0 {
+		t.Errorf("got requests %v in dry run", got)
+	}
+}
+
+func TestIsMutating(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   bool
+	}{
+		{name: "get", method: http.MethodGet, path: "/repos/owner/repo", want: false},
+		{name: "head", method: http.MethodHead, path: "/repos/owner/repo", want: false},
+		{name: "post", method: http.MethodPost, path: "/repos/owner/repo/issues", body: "{}", want: true},
+		{name: "delete", method: http.MethodDelete, path: "/repos/owner/repo/issues/1/labels/a", want: true},
+		{name: "graphql query", method: http.MethodPost, path: "/graphql", body: `{"query":"query{viewer{login}}"}`, want: false},
+		{name: "graphql mutation", method: http.MethodPost, path: "/graphql", body: `{"query":" mutation($input:X!){a}"}`, want: true},
+		{name: "graphql invalid body", method: http.MethodPost, path: "/graphql", body: `{`, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(c.method, "https://api.github.com"+c.path, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := isMutating(req); got != c.want {
+				t.Errorf("got %t, want %t", got, c.want)
+			}
+		})
+	}
+}
+
+func TestDryRunResponseStatus(t *testing.T) {
+	cases := []struct {
+		method string
+		want   string
+	}{
+		{method: http.MethodPost, want: "200 OK"},
+		{method: http.MethodDelete, want: "204 No Content"},
+	}
+
+	tr := &dryRunTransport{}
+
+	for _, c := range cases {
+		t.Run(c.method, func(t *testing.T) {
+			req, err := http.NewRequest(c.method, "https://api.github.com/repos/owner/repo/issues", strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := tr.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Status != c.want {
+				t.Errorf("got status %q, want %q", resp.Status, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// FieldError is the validation error of a field of request.
+type FieldError struct {
+	Resource string
+	Field    string
+	Code     string
+	Message  string
+}
+
+// GitHubError is the error responded by github.
+type GitHubError struct {
+	StatusCode       int
+	Message          string
+	DocumentationURL string
+	Errors           []FieldError
+
+	rateLimited bool
+}
+
+// IsNotFound tells whether the resource doesn't exist.
+func (e *GitHubError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited tells whether the request exceeds the primary or secondary rate limit.
+func (e *GitHubError) IsRateLimited() bool {
+	return e.rateLimited || e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsValidation tells whether the request fails the validation.
+func (e *GitHubError) IsValidation() bool {
+	return e.StatusCode == http.StatusUnprocessableEntity
+}
+
+// AsGitHubError returns the error responded by github in the chain of err.
+// All the helpers of this package wrap the error of github, so it works for
+// the errors returned by them.
+func AsGitHubError(err error) (*GitHubError, bool) {
+	var (
+		errResp  *sdk.ErrorResponse
+		rateErr  *sdk.RateLimitError
+		abuseErr *sdk.AbuseRateLimitError
+	)
+
+	switch {
+	case errors.As(err, &rateErr):
+		return &GitHubError{
+			StatusCode:  statusCode(rateErr.Response),
+			Message:     rateErr.Message,
+			rateLimited: true,
+		}, true
+
+	case errors.As(err, &abuseErr):
+		return &GitHubError{
+			StatusCode:  statusCode(abuseErr.Response),
+			Message:     abuseErr.Message,
+			rateLimited: true,
+		}, true
+
+	case errors.As(err, &errResp):
+		e := &GitHubError{
+			StatusCode:       statusCode(errResp.Response),
+			Message:          errResp.Message,
+			DocumentationURL: errResp.DocumentationURL,
+		}
+
+		for _, v := range errResp.Errors {
+			e.Errors = append(e.Errors, FieldError{
+				Resource: v.Resource,
+				Field:    v.Field,
+				Code:     v.Code,
+				Message:  v.Message,
+			})
+		}
+
+		return e, true
+	}
+
+	return nil, false
+}
+
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+
+	return resp.StatusCode
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+func TestAsGitHubError(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		body      string
+		call      func(Client) error
+		want      GitHubError
+		validate  bool
+		notFound  bool
+		rateLimit bool
+	}{
+		{
+			name:   "validation failed of issue",
+			status: http.StatusUnprocessableEntity,
+			// It is captured from the response of creating an issue with an invalid assignee.
+			body: `{
+				"message": "Validation Failed",
+				"errors": [{"value": "nobody", "resource": "Issue", "field": "assignees", "code": "invalid"}],
+				"documentation_url": "https://docs.github.com/rest/reference/issues#create-an-issue"
+			}`,
+			call: func(c Client) error {
+				_, err := c.CreateIssue("owner", "repo", &sdk.IssueRequest{
+					Title:     sdk.String("bug"),
+					Assignees: &[]string{"nobody"},
+				})
+
+				return err
+			},
+			want: GitHubError{
+				StatusCode:       http.StatusUnprocessableEntity,
+				Message:          "Validation Failed",
+				DocumentationURL: "https://docs.github.com/rest/reference/issues#create-an-issue",
+				Errors:           []FieldError{{Resource: "Issue", Field: "assignees", Code: "invalid"}},
+			},
+			validate: true,
+		},
+		{
+			name:   "label already exists",
+			status: http.StatusUnprocessableEntity,
+			body: `{
+				"message": "Validation Failed",
+				"errors": [{"resource": "Label", "code": "already_exists", "field": "name"}],
+				"documentation_url": "https://docs.github.com/rest/reference/issues#create-a-label"
+			}`,
+			call: func(c Client) error { return c.CreateRepoLabel("owner", "repo", "bug") },
+			want: GitHubError{
+				StatusCode:       http.StatusUnprocessableEntity,
+				Message:          "Validation Failed",
+				DocumentationURL: "https://docs.github.com/rest/reference/issues#create-a-label",
+				Errors:           []FieldError{{Resource: "Label", Field: "name", Code: "already_exists"}},
+			},
+			validate: true,
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"message":"Not Found","documentation_url":"https://docs.github.com/rest"}`,
+			call: func(c Client) error {
+				_, err := c.GetBranchProtection("owner", "repo", "main")
+
+				return err
+			},
+			want: GitHubError{
+				StatusCode:       http.StatusNotFound,
+				Message:          "Not Found",
+				DocumentationURL: "https://docs.github.com/rest",
+			},
+			notFound: true,
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusForbidden,
+			header: map[string]string{"X-RateLimit-Limit": "5000", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "4102444800"},
+			body:   `{"message":"API rate limit exceeded for installation ID 1."}`,
+			call: func(c Client) error {
+				return c.CreateStatus("owner", "repo", "sha", sdk.RepoStatus{State: sdk.String("success")})
+			},
+			want: GitHubError{
+				StatusCode:  http.StatusForbidden,
+				Message:     "API rate limit exceeded for installation ID 1.",
+				rateLimited: true,
+			},
+			rateLimit: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range c.header {
+					w.Header().Set(k, v)
+				}
+
+				w.WriteHeader(c.status)
+				fmt.Fprint(w, c.body)
+			})
+
+			err := c.call(s.client(t))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			// It works through the wrapping of callers too.
+			e, ok := AsGitHubError(fmt.Errorf("failed to handle the event: %w", err))
+			if !ok {
+				t.Fatalf("got error %v, want the error of github", err)
+			}
+
+			if !reflect.DeepEqual(*e, c.want) {
+				t.Errorf("got %+v, want %+v", *e, c.want)
+			}
+
+			if e.IsValidation() != c.validate || e.IsNotFound() != c.notFound || e.IsRateLimited() != c.rateLimit {
+				t.Errorf(
+					"got validation %t, not found %t and rate limited %t",
+					e.IsValidation(), e.IsNotFound(), e.IsRateLimited(),
+				)
+			}
+		})
+	}
+}
+
+func TestAsGitHubErrorOfOtherError(t *testing.T) {
+	if e, ok := AsGitHubError(fmt.Errorf("failed: %w", ErrInvalidPayload)); ok {
+		t.Errorf("got %+v, want none", e)
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const defaultETagStoreSize = 1000
+
+// CachedResponse is the response of a GET request cached by its ETag.
+type CachedResponse struct {
+	ETag   string
+	Header http.Header
+	Body   []byte
+}
+
+// ETagStore keeps the responses of GET requests, so that a request can be made
+// conditional with If-None-Match, and the cached response is served if github
+// responds 304 Not Modified which doesn't count against the rate limit.
+// It can be backed by a shared storage such as Redis for multiple replicas.
+type ETagStore interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, resp CachedResponse)
+}
+
+// WithETagCache makes the client send the conditional GET requests with the
+// ETags cached in store. See NewMemoryETagStore for an in-memory store.
+func WithETagCache(store ETagStore) ClientOption {
+	return func(o *clientOptions) {
+		o.etagStore = store
+	}
+}
+
+// etagTransport makes the GET requests conditional and serves the cached
+// response on 304.
+type etagTransport struct {
+	base  http.RoundTripper
+	store ETagStore
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := etagKey(req)
+
+	cached, ok := t.store.Get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		resp.Body.Close()
+
+		// Keep the headers of the fresh response, such as the rate limit.
+		h := cached.Header.Clone()
+		for k, v := range resp.Header {
+			h[k] = v
+		}
+
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Header = h
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.Body))
+		resp.ContentLength = int64(len(cached.Body))
+
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		t.store.Set(key, CachedResponse{
+			ETag:   resp.Header.Get("ETag"),
+			Header: resp.Header.Clone(),
+			Body:   body,
+		})
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
+}
+
+// etagKey returns the key of request. The credential is part of the key,
+// because the response depends on who requests it.
+func etagKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Header.Get("Authorization")))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get("Accept")))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.String()))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type cachedEntry struct {
+	key  string
+	resp CachedResponse
+}
+
+// memoryETagStore is an ETagStore which keeps the responses in an LRU list
+// of limited size.
+type memoryETagStore struct {
+	lock sync.Mutex
+
+	size  int
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+// NewMemoryETagStore returns an in-memory ETagStore which keeps at most size
+// responses. The size is 1000 if it is not positive.
+func NewMemoryETagStore(size int) ETagStore {
+	if size <= 0 {
+		size = defaultETagStoreSize
+	}
+
+	return &memoryETagStore{
+		size:  size,
+		items: map[string]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+func (s *memoryETagStore) Get(key string) (CachedResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+
+	s.lru.MoveToFront(e)
+
+	return e.Value.(*cachedEntry).resp, true
+}
+
+func (s *memoryETagStore) Set(key string, resp CachedResponse) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		e.Value.(*cachedEntry).resp = resp
+		s.lru.MoveToFront(e)
+
+		return
+	}
+
+	s.items[key] = s.lru.PushFront(&cachedEntry{key: key, resp: resp})
+
+	for s.lru.Len() > s.size {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		delete(s.items, e.Value.(*cachedEntry).key)
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestETagCache(t *testing.T) {
+	var conditional []string
+
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		inm := r.Header.Get("If-None-Match")
+		conditional = append(conditional, inm)
+
+		w.Header().Set("ETag", `"v1"`)
+		if inm == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"number":1,"title":"fix"}`)
+	})
+
+	c := s.client(t, WithETagCache(NewMemoryETagStore(0)))
+
+	for i := 0; i < 2; i++ {
+		pr, err := c.GetSinglePR("owner", "repo", 1)
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+
+		if pr.GetNumber() != 1 || pr.GetTitle() != "fix" {
+			t.Errorf("request %d: got pull request %d %q", i, pr.GetNumber(), pr.GetTitle())
+		}
+	}
+
+	if want := []string{"", `"v1"`}; fmt.Sprint(conditional) != fmt.Sprint(want) {
+		t.Errorf("got If-None-Match %q, want %q", conditional, want)
+	}
+}
+
+func TestMemoryETagStore(t *testing.T) {
+	cases := []struct {
+		name    string
+		size    int
+		keys    []string
+		present []string
+		absent  []string
+	}{
+		{name: "kept", size: 2, keys: []string{"a", "b"}, present: []string{"a", "b"}},
+		{name: "evicted by the size", size: 2, keys: []string{"a", "b", "c"}, present: []string{"b", "c"}, absent: []string{"a"}},
+		{name: "updated is kept", size: 2, keys: []string{"a", "b", "a", "c"}, present: []string{"a", "c"}, absent: []string{"b"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewMemoryETagStore(c.size)
+			for _, k := range c.keys {
+				s.Set(k, CachedResponse{ETag: k})
+			}
+
+			for _, k := range c.present {
+				if v, ok := s.Get(k); !ok || v.ETag != k {
+					t.Errorf("%s is not cached", k)
+				}
+			}
+
+			for _, k := range c.absent {
+				if _, ok := s.Get(k); ok {
+					t.Errorf("%s is not evicted", k)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,69 @@
 package client
 
 import (
+	"fmt"
+	"strings"
+
 	sdk "github.com/google/go-github/v36/github"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// EventTypePing is the event type which github sends when a webhook is created.
+const EventTypePing = "ping"
+
+// IsPing tells whether the event type is the ping event.
+func IsPing(eventType string) bool {
+	return eventType == EventTypePing
+}
+
+// ParseEvent parses the payload of webhook to the event of the type
+// specified by eventType which is the value of X-GitHub-Event header.
+// For example:
+//
+//	e, err := ParseEvent(eventType, payload)
+//	if err != nil {
+//		return err
+//	}
+//
+//	switch e := e.(type) {
+//	case *sdk.PullRequestEvent:
+//		// handle the pull request event
+//	case *sdk.IssueCommentEvent:
+//		// handle the issue comment event
+//	}
+func ParseEvent(eventType string, payload []byte) (interface{}, error) {
+	e, err := sdk.ParseWebHook(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+	}
+
+	return e, nil
+}
+
+// IsSelfEvent tells whether the sender of event is the bot itself, so that the bot
+// doesn't react to its own actions and trigger itself endlessly. The selfLogin
+// matches the sender with or without the "[bot]" suffix of GitHub Apps, for
+// example "robot" matches "robot[bot]".
+func IsSelfEvent(event interface{}, selfLogin string) bool {
+	e, ok := event.(interface{ GetSender() *sdk.User })
+	if !ok || selfLogin == "" {
+		return false
+	}
+
+	login := e.GetSender().GetLogin()
+	if login == "" {
+		return false
+	}
+
+	return strings.EqualFold(
+		strings.TrimSuffix(login, botSuffix),
+		strings.TrimSuffix(selfLogin, botSuffix),
+	)
+}
+
+// botSuffix is the suffix of login of GitHub Apps.
+const botSuffix = "[bot]"
+
 type IssuePRInfo interface {
 	GetOrgRepo() (string, string)
 
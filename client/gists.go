@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// GistFileUpdate is the change of a file of gist.
+type GistFileUpdate struct {
+	// Content is the new content of file. It is kept if empty.
+	Content string
+	// Filename is the new name of file. It is not renamed if empty.
+	Filename string
+	// Delete deletes the file, and the other fields are ignored.
+	Delete bool
+}
+
+// gistFilePatch is a file in the request of updating gist, which is null to
+// delete the file. The sdk can't send null, because it omits the empty fields.
+type gistFilePatch struct {
+	Content  string `json:"content,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type gistPatch struct {
+	Description string                    `json:"description,omitempty"`
+	Files       map[string]*gistFilePatch `json:"files,omitempty"`
+}
+
+// CreateGist creates the gist with the files which are the map of file name to
+// content. The gist is secret if it is not public.
+func (cl client) CreateGist(description string, public bool, files map[string]string) (*sdk.Gist, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	g := &sdk.Gist{
+		Description: sdk.String(description),
+		Public:      sdk.Bool(public),
+		Files:       make(map[sdk.GistFilename]sdk.GistFile, len(files)),
+	}
+
+	for name, content := range files {
+		g.Files[sdk.GistFilename(name)] = sdk.GistFile{Content: sdk.String(content)}
+	}
+
+	v, _, err := cl.c.Gists.Create(ctx, g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gist: %w", err)
+	}
+
+	return v, nil
+}
+
+// GetGist returns the gist with the content of its files.
+func (cl client) GetGist(id string) (*sdk.Gist, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, _, err := cl.c.Gists.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gist %s: %w", id, err)
+	}
+
+	return v, nil
+}
+
+// UpdateGist changes the files of gist, which are the map of the current file
+// name to the change. The file which doesn't exist is added, and the files not
+// in the map are kept. The description is kept if it is empty.
+func (cl client) UpdateGist(id, description string, files map[string]GistFileUpdate) (*sdk.Gist, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	req, err := cl.c.NewRequest("PATCH", "gists/"+id, newGistPatch(description, files))
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(sdk.Gist)
+	if _, err := cl.c.Do(ctx, req, v); err != nil {
+		return nil, fmt.Errorf("failed to update gist %s: %w", id, err)
+	}
+
+	return v, nil
+}
+
+func newGistPatch(description string, files map[string]GistFileUpdate) *gistPatch {
+	p := &gistPatch{
+		Description: description,
+		Files:       make(map[string]*gistFilePatch, len(files)),
+	}
+
+	for name, f := range files {
+		if f.Delete {
+			// The file is deleted by null.
+			p.Files[name] = nil
+		} else {
+			p.Files[name] = &gistFilePatch{Content: f.Content, Filename: f.Filename}
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewGistPatch(t *testing.T) {
+	cases := []struct {
+		name        string
+		description string
+		files       map[string]GistFileUpdate
+		want        string
+	}{
+		{
+			name:  "delete file",
+			files: map[string]GistFileUpdate{"old.txt": {Delete: true, Content: "ignored"}},
+			want:  `{"files":{"old.txt":null}}`,
+		},
+		{
+			name:  "add, change and delete files",
+			files: map[string]GistFileUpdate{"a.txt": {Content: "a"}, "b.txt": {Delete: true}},
+			want:  `{"files":{"a.txt":{"content":"a"},"b.txt":null}}`,
+		},
+		{
+			name:  "rename file",
+			files: map[string]GistFileUpdate{"a.txt": {Filename: "b.txt"}},
+			want:  `{"files":{"a.txt":{"filename":"b.txt"}}}`,
+		},
+		{
+			name:        "description only",
+			description: "state",
+			want:        `{"description":"state"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := json.Marshal(newGistPatch(c.description, c.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != c.want {
+				t.Errorf("got %s, want %s", b, c.want)
+			}
+		})
+	}
+}
+
+func TestUpdateGistDeletesFile(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1","files":{"keep.txt":{"content":"k"}}}`)
+	})
+
+	g, err := s.client(t).UpdateGist("1", "", map[string]GistFileUpdate{"old.txt": {Delete: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := s.received(), []string{"PATCH /gists/1"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+
+	if got, want := s.bodies[0], `{"files":{"old.txt":null}}`; got != want {
+		t.Errorf("got body %s, want %s", got, want)
+	}
+
+	if _, ok := g.Files["keep.txt"]; !ok || len(g.Files) != 1 {
+		t.Errorf("got files %v", g.Files)
+	}
+}
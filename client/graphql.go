@@ -0,0 +1,569 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// ErrAutoMergeDisabled is returned when enabling the auto-merge of pull request
+// in the repo which doesn't allow it.
+var ErrAutoMergeDisabled = errors.New("auto-merge is not allowed in the repo")
+
+var (
+	// ErrAlreadyDraft is returned when converting the draft pull request to draft.
+	ErrAlreadyDraft = errors.New("pull request is already a draft")
+
+	// ErrAlreadyReady is returned when marking the pull request which is not
+	// a draft as ready for review.
+	ErrAlreadyReady = errors.New("pull request is already ready for review")
+)
+
+// GraphQLClient is the client for GitHub GraphQL API.
+type GraphQLClient struct {
+	c *githubv4.Client
+
+	ids *nodeIDCache
+
+	// timeout is the timeout of a whole call of method, see WithCallTimeout.
+	timeout time.Duration
+}
+
+// NewGraphQLClient returns a GraphQLClient which is configured in the same
+// way as the REST client. The invalid options are logged and ignored, use
+// NewGraphQLClientE to get the error instead.
+func NewGraphQLClient(getToken func() []byte, opts ...ClientOption) *GraphQLClient {
+	c, err := NewGraphQLClientE(getToken, ignoreInvalidOptions(opts)...)
+	if err != nil {
+		defaultLogger.Error("failed to create the graphql client, the default one is used", LogFields{"error": err.Error()})
+
+		c, _ = NewGraphQLClientE(getToken)
+	}
+
+	return c
+}
+
+// NewGraphQLClientE is the same as NewGraphQLClient except that it returns
+// the error of invalid options.
+func NewGraphQLClientE(getToken func() []byte, opts ...ClientOption) (*GraphQLClient, error) {
+	o, err := newClientOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tc, _, err := newHTTPClient(getToken, o)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := &GraphQLClient{ids: newNodeIDCache(), timeout: o.wholeCallTimeout()}
+
+	if o.baseURL == "" {
+		cl.c = githubv4.NewClient(tc)
+
+		return cl, nil
+	}
+
+	// The GraphQL endpoint of GitHub Enterprise Server is /api/graphql.
+	cl.c = githubv4.NewEnterpriseClient(strings.TrimSuffix(o.baseURL, "v3/")+"graphql", tc)
+
+	return cl, nil
+}
+
+// newContext returns a context which will be canceled after the timeout of call.
+func (cl *GraphQLClient) newContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cl.timeout)
+}
+
+// Query executes a single GraphQL query request. The q is a pointer to the
+// struct that describes the query, and it will be populated with the response.
+func (cl *GraphQLClient) Query(ctx context.Context, q interface{}, vars map[string]interface{}) error {
+	return cl.c.Query(ctx, q, vars)
+}
+
+// ReviewThread is a review thread of pull request.
+type ReviewThread struct {
+	ID         string
+	Path       string
+	IsResolved bool
+	IsOutdated bool
+}
+
+// ListReviewThreads returns all the review threads of pull request.
+func (cl *GraphQLClient) ListReviewThreads(org, repo string, number int) ([]ReviewThread, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes    []ReviewThread
+					PageInfo struct {
+						EndCursor   githubv4.String
+						HasNextPage bool
+					}
+				} `graphql:"reviewThreads(first: 100, after: $cursor)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(org),
+		"name":   githubv4.String(repo),
+		"number": githubv4.Int(number),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	var threads []ReviewThread
+	for {
+		if err := cl.Query(ctx, &q, vars); err != nil {
+			return nil, fmt.Errorf("failed to list review threads of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		v := &q.Repository.PullRequest.ReviewThreads
+		threads = append(threads, v.Nodes...)
+
+		if !v.PageInfo.HasNextPage {
+			break
+		}
+
+		vars["cursor"] = githubv4.NewString(v.PageInfo.EndCursor)
+	}
+
+	return threads, nil
+}
+
+// Mutate executes a single GraphQL mutation request. The m is a pointer to the
+// struct that describes the mutation, and it will be populated with the response.
+func (cl *GraphQLClient) Mutate(ctx context.Context, m interface{}, input githubv4.Input, vars map[string]interface{}) error {
+	return cl.c.Mutate(ctx, m, input, vars)
+}
+
+// MinimizeComment hides the comment of issue, pull request or review, which is
+// specified by the node id rather than the id of REST API. See GetCommentNodeID.
+// The reason is one of OUTDATED, RESOLVED, DUPLICATE, OFF_TOPIC, SPAM and ABUSE.
+func (cl *GraphQLClient) MinimizeComment(nodeID, reason string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var m struct {
+		MinimizeComment struct {
+			MinimizedComment struct {
+				IsMinimized bool
+			}
+		} `graphql:"minimizeComment(input: $input)"`
+	}
+
+	input := githubv4.MinimizeCommentInput{
+		SubjectID:  githubv4.ID(nodeID),
+		Classifier: githubv4.ReportedContentClassifiers(strings.ToUpper(reason)),
+	}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to minimize comment %s: %w", nodeID, err)
+	}
+
+	return nil
+}
+
+// TransferredIssue is the issue in the target repo after transfer.
+type TransferredIssue struct {
+	Number int
+	URL    string
+}
+
+// TransferIssue transfers the issue to the target repo which is "owner/repo" or
+// the name of repo in the same org. Github rejects it if the target repo has
+// disabled the issues, or it is owned by another user or org, or the user can't
+// push to both of the repos.
+func (cl *GraphQLClient) TransferIssue(org, repo string, number int, targetRepo string) (TransferredIssue, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	targetOrg := org
+	if i := strings.Index(targetRepo, "/"); i >= 0 {
+		targetOrg, targetRepo = targetRepo[:i], targetRepo[i+1:]
+	}
+
+	pr := PRInfo{org, repo, number}
+
+	issueID, err := cl.IssueNodeID(org, repo, number)
+	if err != nil {
+		return TransferredIssue{}, err
+	}
+
+	var q struct {
+		Target struct {
+			ID        githubv4.ID
+			HasIssues bool `graphql:"hasIssuesEnabled"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	vars := map[string]interface{}{
+		"owner": githubv4.String(targetOrg),
+		"name":  githubv4.String(targetRepo),
+	}
+
+	if err := cl.Query(ctx, &q, vars); err != nil {
+		return TransferredIssue{}, fmt.Errorf("failed to get the target repo to transfer issue %s: %w", pr, err)
+	}
+
+	if !q.Target.HasIssues {
+		return TransferredIssue{}, fmt.Errorf(
+			"failed to transfer issue %s: issues are disabled in %s/%s", pr, targetOrg, targetRepo,
+		)
+	}
+
+	var m struct {
+		TransferIssue struct {
+			Issue struct {
+				Number int
+				URL    string
+			}
+		} `graphql:"transferIssue(input: $input)"`
+	}
+
+	input := githubv4.TransferIssueInput{
+		IssueID:      githubv4.ID(issueID),
+		RepositoryID: q.Target.ID,
+	}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return TransferredIssue{}, fmt.Errorf("failed to transfer issue %s to %s/%s: %w", pr, targetOrg, targetRepo, err)
+	}
+
+	// The number in the source repo refers to the transferred issue no more.
+	cl.ids.remove(issueNodeIDKey(org, repo, number))
+
+	return TransferredIssue{
+		Number: m.TransferIssue.Issue.Number,
+		URL:    m.TransferIssue.Issue.URL,
+	}, nil
+}
+
+// ResolveReviewThread marks the review thread as resolved. The thread id is the
+// node id of GraphQL, see ListReviewThreads.
+func (cl *GraphQLClient) ResolveReviewThread(threadID string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var m struct {
+		ResolveReviewThread struct {
+			Thread struct {
+				IsResolved bool
+			}
+		} `graphql:"resolveReviewThread(input: $input)"`
+	}
+
+	input := githubv4.ResolveReviewThreadInput{ThreadID: githubv4.ID(threadID)}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to resolve review thread %s: %w", threadID, err)
+	}
+
+	return nil
+}
+
+// UnresolveReviewThread marks the review thread as unresolved.
+func (cl *GraphQLClient) UnresolveReviewThread(threadID string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var m struct {
+		UnresolveReviewThread struct {
+			Thread struct {
+				IsResolved bool
+			}
+		} `graphql:"unresolveReviewThread(input: $input)"`
+	}
+
+	input := githubv4.UnresolveReviewThreadInput{ThreadID: githubv4.ID(threadID)}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to unresolve review thread %s: %w", threadID, err)
+	}
+
+	return nil
+}
+
+// ThreadComment is the first comment of a review thread, which starts the conversation.
+type ThreadComment struct {
+	Author string
+	Body   string
+	URL    string
+}
+
+// UnresolvedThread is a review thread which is not resolved.
+type UnresolvedThread struct {
+	ReviewThread
+
+	FirstComment ThreadComment
+}
+
+// ListUnresolvedThreads returns the unresolved review threads of pull request
+// with their first comments, so that a bot can block the merge until all the
+// conversations are resolved. The resolution state is not exposed by REST API.
+func (cl *GraphQLClient) ListUnresolvedThreads(org, repo string, number int) ([]UnresolvedThread, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	type threadNode struct {
+		ID         string
+		Path       string
+		IsResolved bool
+		IsOutdated bool
+		Comments   struct {
+			Nodes []struct {
+				Author struct {
+					Login string
+				}
+				Body string
+				URL  string
+			}
+		} `graphql:"comments(first: 1)"`
+	}
+
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes    []threadNode
+					PageInfo struct {
+						EndCursor   githubv4.String
+						HasNextPage bool
+					}
+				} `graphql:"reviewThreads(first: 100, after: $cursor)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(org),
+		"name":   githubv4.String(repo),
+		"number": githubv4.Int(number),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	var threads []UnresolvedThread
+	for {
+		if err := cl.Query(ctx, &q, vars); err != nil {
+			return nil, fmt.Errorf("failed to list review threads of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		v := &q.Repository.PullRequest.ReviewThreads
+		for i := range v.Nodes {
+			n := &v.Nodes[i]
+			if n.IsResolved {
+				continue
+			}
+
+			t := UnresolvedThread{
+				ReviewThread: ReviewThread{
+					ID:         n.ID,
+					Path:       n.Path,
+					IsOutdated: n.IsOutdated,
+				},
+			}
+
+			if len(n.Comments.Nodes) > 0 {
+				c := &n.Comments.Nodes[0]
+				t.FirstComment = ThreadComment{
+					Author: c.Author.Login,
+					Body:   c.Body,
+					URL:    c.URL,
+				}
+			}
+
+			threads = append(threads, t)
+		}
+
+		if !v.PageInfo.HasNextPage {
+			break
+		}
+
+		vars["cursor"] = githubv4.NewString(v.PageInfo.EndCursor)
+	}
+
+	return threads, nil
+}
+
+// EnableAutoMerge makes github merge the pull request by the method once all the
+// requirements are met, such as the required checks and reviews. The method is
+// one of "merge", "squash" and "rebase", and it is "merge" if empty. It returns
+// ErrAutoMergeDisabled if the repo doesn't allow auto-merge.
+func (cl *GraphQLClient) EnableAutoMerge(org, repo string, number int, method string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr := PRInfo{org, repo, number}
+
+	var q struct {
+		Repository struct {
+			AutoMergeAllowed bool
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	vars := map[string]interface{}{
+		"owner": githubv4.String(org),
+		"name":  githubv4.String(repo),
+	}
+
+	if err := cl.Query(ctx, &q, vars); err != nil {
+		return fmt.Errorf("failed to check whether auto-merge is allowed for %s: %w", pr, err)
+	}
+
+	if !q.Repository.AutoMergeAllowed {
+		return fmt.Errorf("failed to enable auto-merge of %s: %w", pr, ErrAutoMergeDisabled)
+	}
+
+	id, err := cl.IssueNodeID(org, repo, number)
+	if err != nil {
+		return err
+	}
+
+	var m struct {
+		EnablePullRequestAutoMerge struct {
+			PullRequest struct {
+				Number int
+			}
+		} `graphql:"enablePullRequestAutoMerge(input: $input)"`
+	}
+
+	input := githubv4.EnablePullRequestAutoMergeInput{PullRequestID: githubv4.ID(id)}
+	if method != "" {
+		v := githubv4.PullRequestMergeMethod(strings.ToUpper(method))
+		input.MergeMethod = &v
+	}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to enable auto-merge of %s: %w", pr, err)
+	}
+
+	return nil
+}
+
+// DisableAutoMerge cancels the auto-merge of pull request.
+func (cl *GraphQLClient) DisableAutoMerge(org, repo string, number int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr := PRInfo{org, repo, number}
+
+	id, err := cl.IssueNodeID(org, repo, number)
+	if err != nil {
+		return err
+	}
+
+	var m struct {
+		DisablePullRequestAutoMerge struct {
+			PullRequest struct {
+				Number int
+			}
+		} `graphql:"disablePullRequestAutoMerge(input: $input)"`
+	}
+
+	input := githubv4.DisablePullRequestAutoMergeInput{PullRequestID: githubv4.ID(id)}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to disable auto-merge of %s: %w", pr, err)
+	}
+
+	return nil
+}
+
+// MarkPullRequestReady marks the draft pull request as ready for review.
+// It returns ErrAlreadyReady if the pull request is not a draft.
+func (cl *GraphQLClient) MarkPullRequestReady(org, repo string, number int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr := PRInfo{org, repo, number}
+
+	id, isDraft, err := cl.pullRequestDraft(ctx, org, repo, number)
+	if err != nil {
+		return err
+	}
+
+	if !isDraft {
+		return fmt.Errorf("failed to mark %s as ready: %w", pr, ErrAlreadyReady)
+	}
+
+	var m struct {
+		MarkPullRequestReadyForReview struct {
+			PullRequest struct {
+				IsDraft bool
+			}
+		} `graphql:"markPullRequestReadyForReview(input: $input)"`
+	}
+
+	input := githubv4.MarkPullRequestReadyForReviewInput{PullRequestID: id}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to mark %s as ready: %w", pr, err)
+	}
+
+	return nil
+}
+
+// ConvertPullRequestToDraft converts the pull request to draft, which REST API
+// supports only when creating it. It returns ErrAlreadyDraft if the pull request
+// is a draft.
+func (cl *GraphQLClient) ConvertPullRequestToDraft(org, repo string, number int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr := PRInfo{org, repo, number}
+
+	id, isDraft, err := cl.pullRequestDraft(ctx, org, repo, number)
+	if err != nil {
+		return err
+	}
+
+	if isDraft {
+		return fmt.Errorf("failed to convert %s to draft: %w", pr, ErrAlreadyDraft)
+	}
+
+	var m struct {
+		ConvertPullRequestToDraft struct {
+			PullRequest struct {
+				IsDraft bool
+			}
+		} `graphql:"convertPullRequestToDraft(input: $input)"`
+	}
+
+	input := githubv4.ConvertPullRequestToDraftInput{PullRequestID: id}
+
+	if err := cl.Mutate(ctx, &m, input, nil); err != nil {
+		return fmt.Errorf("failed to convert %s to draft: %w", pr, err)
+	}
+
+	return nil
+}
+
+// pullRequestDraft returns the node id of pull request and whether it is a draft.
+// The node id is cached, see IssueNodeID, while the draft state is always queried.
+func (cl *GraphQLClient) pullRequestDraft(ctx context.Context, org, repo string, number int) (githubv4.ID, bool, error) {
+	id, err := cl.IssueNodeID(org, repo, number)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var q struct {
+		Node struct {
+			PullRequest struct {
+				IsDraft bool
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $id)"`
+	}
+
+	if err := cl.Query(ctx, &q, map[string]interface{}{"id": githubv4.ID(id)}); err != nil {
+		return nil, false, fmt.Errorf("failed to get the draft state of %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return githubv4.ID(id), q.Node.PullRequest.IsDraft, nil
+}
@@ -1,16 +1,25 @@
 package client
 
 import (
+	"bytes"
+	"container/list"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v36/github"
-	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/yaml"
 )
 
@@ -23,92 +32,488 @@ type hmacSecret struct {
 // hmacsForRepo contains all hmac tokens configured for a repo, org or globally.
 type hmacsForRepo []hmacSecret
 
+const (
+	sha1Prefix   = "sha1="
+	sha256Prefix = "sha256="
+
+	// HmacLevelRepoEvent means the hmac is configured for the event type of the repo.
+	HmacLevelRepoEvent = "repo-event"
+	// HmacLevelRepo means the hmac is configured for the repo.
+	HmacLevelRepo = "repo"
+	// HmacLevelRepoGlob means the hmac is configured for a glob pattern matching the repo.
+	HmacLevelRepoGlob = "repo-glob"
+	// HmacLevelOrgEvent means the hmac is configured for the event type of the org.
+	HmacLevelOrgEvent = "org-event"
+	// HmacLevelOrg means the hmac is configured for the org.
+	HmacLevelOrg = "org"
+	// HmacLevelInstallation means the hmac is configured for the installation of GitHub App.
+	HmacLevelInstallation = "installation"
+	// HmacLevelGlobal means the hmac is configured globally.
+	HmacLevelGlobal = "global"
+)
+
+// HmacMatch describes the hmac which the signature of payload matches.
+type HmacMatch struct {
+	// Level is the configuration level of the hmac, one of repo-event, repo,
+	// repo-glob, org-event, org, installation and global.
+	Level string
+	// Index is the index of the hmac among the unexpired hmacs of that level.
+	Index int
+	// CreatedAt is the time when the hmac is created.
+	CreatedAt time.Time
+}
+
 type genericEvent struct {
-	Sender github.User       `json:"sender"`
-	Repo   github.Repository `json:"repository"`
+	Sender       github.User         `json:"sender"`
+	Repo         github.Repository   `json:"repository"`
+	Org          github.Organization `json:"organization"`
+	Installation github.Installation `json:"installation"`
 }
 
+// owner returns the owner by which the hmacs are looked up. It is the full name
+// of repo if the event has repository, otherwise it is the login of organization
+// or the account of installation, for example the org membership and installation
+// events. It is empty if none of them exists, and only the global hmacs are used.
+func (e *genericEvent) owner() string {
+	if v := e.Repo.GetFullName(); v != "" {
+		return v
+	}
+
+	if v := e.Org.GetLogin(); v != "" {
+		return v
+	}
+
+	return e.Installation.GetAccount().GetLogin()
+}
+
+// installationKeyPrefix is the prefix of key of the hmacs configured for an
+// installation of GitHub App, which is followed by the installation id.
+const installationKeyPrefix = "installation:"
+
+// installationKey returns the key of hmacs configured for the installation which
+// sends the event, for example "installation:123". It is empty if the event is
+// not sent by a GitHub App.
+func (e *genericEvent) installationKey() string {
+	if id := e.Installation.GetID(); id != 0 {
+		return installationKeyPrefix + strconv.FormatInt(id, 10)
+	}
+
+	return ""
+}
+
+// utf8BOM is the byte order mark of UTF-8 which some proxies prepend to the body.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// parseGenericEvent parses the fields to select the hmacs from payload. It is
+// tolerant of the UTF-8 BOM and the trailing data, so that a quirk of encoding
+// doesn't reject the payload of which the signature is valid. The hmac is still
+// computed over the original payload.
+func parseGenericEvent(payload []byte) (genericEvent, error) {
+	var e genericEvent
+
+	err := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(payload, utf8BOM))).Decode(&e)
+
+	return e, err
+}
+
+var (
+	// ErrInvalidPayload is returned when the payload is not a valid github event.
+	ErrInvalidPayload = errors.New("invalid payload")
+
+	// ErrBadSignatureFormat is returned when the signature has an unknown
+	// algorithm prefix or is not hex encoded.
+	ErrBadSignatureFormat = errors.New("bad signature format")
+
+	// ErrNoHmacConfigured is returned when there is no hmac configured for the repo.
+	ErrNoHmacConfigured = errors.New("no hmac configured")
+
+	// ErrInvalidSecretFile is returned when the hmac secret file can't be parsed.
+	ErrInvalidSecretFile = errors.New("invalid hmac secret file")
+
+	// ErrSignatureMismatch is returned when the signature matches none of the hmacs.
+	ErrSignatureMismatch = errors.New("signature mismatch")
+)
+
 // ValidatePayload ensures that the request payload signature matches the key.
+// The signature can be either the value of X-Hub-Signature (sha1=...) or
+// the value of X-Hub-Signature-256 (sha256=...).
 func ValidatePayload(payload []byte, sig string, tokenGenerator func() []byte) bool {
-	var event genericEvent
-	if err := json.Unmarshal(payload, &event); err != nil {
-		logrus.WithError(err).Info("validatePayload couldn't unmarshal the github event payload")
+	return ValidatePayloadE(payload, sig, tokenGenerator) == nil
+}
 
-		return false
+// ValidatePayloadE is the same as ValidatePayload except that it returns
+// the reason why the validation failed.
+func ValidatePayloadE(payload []byte, sig string, tokenGenerator func() []byte) error {
+	return ValidatePayloadCtx(context.Background(), payload, sig, tokenGenerator)
+}
+
+// ValidatePayloadCtx is the same as ValidatePayloadE except that it stops
+// when the ctx is done.
+func ValidatePayloadCtx(ctx context.Context, payload []byte, sig string, tokenGenerator func() []byte) error {
+	_, err := newDefaultValidator(tokenGenerator).ValidateEventCtx(ctx, "", payload, sig)
+
+	return err
+}
+
+// ValidatePayloadWithMatch is the same as ValidatePayloadE except that it
+// also returns the hmac which the signature matches.
+func ValidatePayloadWithMatch(payload []byte, sig string, tokenGenerator func() []byte) (HmacMatch, error) {
+	return newDefaultValidator(tokenGenerator).ValidateWithMatch(payload, sig)
+}
+
+// PayloadSignature returns the signature that matches the payload.
+func PayloadSignature(payload []byte, key []byte) string {
+	return PayloadSignatureWith(AlgorithmSHA1, payload, key)
+}
+
+// PayloadSignature256 returns the sha256 signature that matches the payload.
+func PayloadSignature256(payload []byte, key []byte) string {
+	return PayloadSignatureWith(AlgorithmSHA256, payload, key)
+}
+
+// PayloadSignatureWith returns the signature of the algorithm that matches
+// the payload. It returns empty string if the algorithm is unknown.
+func PayloadSignatureWith(algorithm string, payload []byte, key []byte) string {
+	var hashFunc func() hash.Hash
+
+	switch algorithm {
+	case AlgorithmSHA1:
+		hashFunc = sha1.New
+
+	case AlgorithmSHA256:
+		hashFunc = sha256.New
+
+	default:
+		return ""
 	}
 
-	if !strings.HasPrefix(sig, "sha1=") {
-		return false
+	mac := hmac.New(hashFunc, key)
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+
+	return algorithm + "=" + hex.EncodeToString(sum)
+}
+
+// selfCheckPayload is the fixed payload signed by SelfCheck.
+var selfCheckPayload = []byte(`{"action":"self-check","repository":{"full_name":"self/check"}}`)
+
+// SelfCheck signs a fixed payload with the secret by both sha1 and sha256, and
+// validates the signatures with the secret, so that the signing setup can be
+// verified at startup or in a health check handler.
+func SelfCheck(secret string) error {
+	if secret == "" {
+		return errors.New("the secret is empty")
 	}
 
-	sig = sig[5:]
-	sb, err := hex.DecodeString(sig)
+	f, err := BuildSecretFile(map[string]string{"*": secret})
 	if err != nil {
-		return false
+		return err
 	}
 
-	hmacs, err := extractHmacs(event.Repo.GetFullName(), tokenGenerator)
-	if err != nil {
-		logrus.WithError(err).Error("couldn't unmarshal the hmac secret")
+	tokenGenerator := func() []byte { return f }
 
-		return false
+	for _, algorithm := range []string{AlgorithmSHA1, AlgorithmSHA256} {
+		sig := PayloadSignatureWith(algorithm, selfCheckPayload, []byte(secret))
+		if err := ValidatePayloadE(selfCheckPayload, sig, tokenGenerator); err != nil {
+			return fmt.Errorf("self check of %s failed: %w", algorithm, err)
+		}
 	}
 
-	// If we have a match with any valid hmac, we can validate successfully.
-	for _, key := range hmacs {
-		mac := hmac.New(sha1.New, key)
-		mac.Write(payload)
-		expected := mac.Sum(nil)
+	return nil
+}
+
+// parseSignature returns the hash function indicated by the prefix of sig
+// and the hex encoded digest. The hash function is nil if the prefix is unknown.
+func parseSignature(sig string) (func() hash.Hash, string) {
+	switch {
+	case strings.HasPrefix(sig, sha256Prefix):
+		return sha256.New, sig[len(sha256Prefix):]
+
+	case strings.HasPrefix(sig, sha1Prefix):
+		return sha1.New, sig[len(sha1Prefix):]
+
+	default:
+		return nil, ""
+	}
+}
+
+// defaultSecretCacheSize is the least number of secret files cached by a
+// Validator, which is enough for a few generators and the rotation of their files.
+const defaultSecretCacheSize = 16
+
+// secretCacheSize returns the size of cache for the number of generators, so
+// that the current and the previous file of each generator can be cached and
+// the generators will not evict the files of each other.
+func secretCacheSize(generators int) int {
+	if n := 2 * (generators + 1); n > defaultSecretCacheSize {
+		return n
+	}
 
-		if hmac.Equal(sb, expected) {
-			return true
+	return defaultSecretCacheSize
+}
+
+// parsedSecret is the parsed content of hmac secret file.
+type parsedSecret struct {
+	sum    [sha256.Size]byte
+	tokens map[string]hmacsForRepo
+	err    error
+}
+
+// hmacSecretCache caches the parsed hmac secret files by the hash of their
+// contents, so that a file will not be unmarshalled again until its content
+// changes. The files of several generators can be cached at the same time,
+// and the least recently used one is evicted if there are more than size.
+type hmacSecretCache struct {
+	lock sync.Mutex
+
+	size  int
+	items map[[sha256.Size]byte]*list.Element
+	lru   *list.List
+}
+
+func newHmacSecretCache(size int) *hmacSecretCache {
+	return &hmacSecretCache{
+		size:  size,
+		items: map[[sha256.Size]byte]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+// parse returns the hmacs of the secret file. It is safe for concurrent use.
+func (c *hmacSecretCache) parse(t []byte) (map[string]hmacsForRepo, error) {
+	sum := sha256.Sum256(t)
+
+	c.lock.Lock()
+	if e, ok := c.items[sum]; ok {
+		c.lru.MoveToFront(e)
+		v := e.Value.(*parsedSecret)
+		c.lock.Unlock()
+
+		return v.tokens, v.err
+	}
+	c.lock.Unlock()
+
+	tokens := map[string]hmacsForRepo{}
+	err := yaml.Unmarshal(t, &tokens)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.items[sum]; !ok {
+		c.items[sum] = c.lru.PushFront(&parsedSecret{sum: sum, tokens: tokens, err: err})
+
+		for c.lru.Len() > c.size {
+			e := c.lru.Back()
+			c.lru.Remove(e)
+			delete(c.items, e.Value.(*parsedSecret).sum)
 		}
 	}
 
-	return false
+	return tokens, err
 }
 
-// PayloadSignature returns the signature that matches the payload.
-func PayloadSignature(payload []byte, key []byte) string {
-	mac := hmac.New(sha1.New, key)
-	mac.Write(payload)
-	sum := mac.Sum(nil)
+// ValidateSecretFile checks the content of hmac secret file, so that a
+// misconfigured file can be found at startup rather than at the first webhook.
+// The file is interpreted as the hierarchical format if it is a yaml map,
+// otherwise as the legacy single token format.
+func ValidateSecretFile(raw []byte) error {
+	var v interface{}
+	if err := yaml.Unmarshal(raw, &v); err != nil || !isMap(v) {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			return errors.New("the hmac secret file is empty")
+		}
+
+		if !isSingleToken(raw) {
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSecretFile, err)
+			}
+
+			return fmt.Errorf("%w: it is neither a map nor a single line token", ErrInvalidSecretFile)
+		}
+
+		defaultLogger.Info("the hmac secret file is interpreted as the legacy single token format", nil)
+
+		return nil
+	}
 
-	return "sha1=" + hex.EncodeToString(sum)
+	defaultLogger.Info("the hmac secret file is interpreted as the hierarchical format", nil)
+
+	repoToTokenMap := map[string]hmacsForRepo{}
+	if err := yaml.UnmarshalStrict(raw, &repoToTokenMap); err != nil {
+		if err1 := yaml.Unmarshal(raw, &repoToTokenMap); err1 != nil {
+			return fmt.Errorf("invalid hierarchical hmac secret file: %v", err1)
+		}
+
+		// The strict parsing fails on the duplicate keys or unknown fields.
+		defaultLogger.Info(
+			"the hmac secret file has duplicate keys or unknown fields",
+			LogFields{"error": err.Error()},
+		)
+	}
+
+	for key, tokens := range repoToTokenMap {
+		for i := range tokens {
+			if tokens[i].Value == "" {
+				return fmt.Errorf("the value of token %d for %s is empty", i, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isSingleToken tells whether the content is a single line token of the legacy format.
+// It is not if the content has multiple lines or looks like a yaml/json mapping.
+func isSingleToken(t []byte) bool {
+	v := string(bytes.TrimSpace(t))
+	if v == "" || strings.ContainsAny(v, "\r\n") {
+		return false
+	}
+
+	return !strings.Contains(v, ": ") && !strings.HasSuffix(v, ":") && !strings.HasPrefix(v, "{")
+}
+
+func isMap(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+
+	return ok
+}
+
+// hmacLevel is a key of hmac secret file and the level it stands for.
+type hmacLevel struct {
+	key   string
+	level string
 }
 
 // extractHmacs returns all *valid* HMAC tokens for given repository/organization.
 // It considers only the tokens at the most specific level configured for the given repo.
 // For example : if a token for repo is present and it doesn't match the repo, we will
 // not try to find a match with org level token. However if no token is present for repo,
-// we will try to match with org level.
-func extractHmacs(repo string, tokenGenerator func() []byte) ([][]byte, error) {
-	t := tokenGenerator()
-	repoToTokenMap := map[string]hmacsForRepo{}
-
-	if err := yaml.Unmarshal(t, &repoToTokenMap); err != nil {
+// we will try to match with org level. The Validator can also try the global tokens
+// after them, see WithGlobalFallbackAlways.
+// It also returns the level at which the tokens are configured.
+// The t is the content of secret file, and it is parsed and cached by cache
+// until its content changes.
+// The tokens older than maxAge are ignored, and if no token is left for a level,
+// we will try to match with the next level.
+//
+// The key can also be a glob pattern of repo such as "org/infra-*" which follows
+// the semantics of path.Match. It is tried after the repo and before the org.
+// If multiple patterns match, the longer one is preferred.
+//
+// If the event type is not empty, the tokens configured for the event type are
+// preferred. Such tokens are configured with key of "owner/repo:event" or "org:event",
+// for example "owner/repo:push". The lookup order is "owner/repo:event", "owner/repo",
+// the glob patterns, "org:event", "org", "installation:id" and "*".
+//
+// The repo can also be the name of org only, in which case the lookup starts from
+// "org:event", or empty, in which case only the installation and "*" are tried.
+// The installation is the key of installation which sends the event, see
+// genericEvent.installationKey, and it is skipped if empty.
+func extractHmacs(
+	repo, installation, eventType string, t []byte, cache *hmacSecretCache,
+	maxAge time.Duration, log Logger,
+) (string, hmacsForRepo, error) {
+	repoToTokenMap, err := cache.parse(t)
+	if err != nil {
 		// To keep backward compatibility, we are going to assume that in case of error,
-		// whole file is a single line hmac token.
-		logrus.WithError(err).Trace("Couldn't unmarshal the hmac secret as hierarchical file. Parsing as single token format")
+		// whole file is a single line hmac token if it looks like so.
+		if !isSingleToken(t) {
+			return "", nil, fmt.Errorf("%w: %v", ErrInvalidSecretFile, err)
+		}
 
-		return [][]byte{t}, nil
+		log.Debug(
+			"Couldn't unmarshal the hmac secret as hierarchical file. Parsing as single token format",
+			LogFields{"error": err.Error()},
+		)
+
+		return HmacLevelGlobal, hmacsForRepo{{Value: string(t)}}, nil
 	}
 
 	orgName := strings.Split(repo, "/")[0]
 
-	if val, ok := repoToTokenMap[repo]; ok {
-		return extractTokens(val), nil
+	var levels []hmacLevel
+	if strings.Contains(repo, "/") {
+		if eventType != "" {
+			levels = append(levels, hmacLevel{repo + ":" + eventType, HmacLevelRepoEvent})
+		}
+
+		levels = append(levels, hmacLevel{repo, HmacLevelRepo})
+		levels = append(levels, matchRepoGlobs(repoToTokenMap, repo)...)
+	}
+
+	if orgName != "" {
+		if eventType != "" {
+			levels = append(levels, hmacLevel{orgName + ":" + eventType, HmacLevelOrgEvent})
+		}
+
+		levels = append(levels, hmacLevel{orgName, HmacLevelOrg})
+	}
+
+	if installation != "" {
+		levels = append(levels, hmacLevel{installation, HmacLevelInstallation})
+	}
+
+	levels = append(levels, hmacLevel{"*", HmacLevelGlobal})
+
+	for _, item := range levels {
+		if val, ok := repoToTokenMap[item.key]; ok {
+			if val = filterExpiredTokens(val, maxAge); len(val) > 0 {
+				return item.level, val, nil
+			}
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: invalid content in secret file, global token doesn't exist or all the tokens are expired", ErrNoHmacConfigured)
+}
+
+// matchRepoGlobs returns the keys which are glob patterns matching the repo.
+// The longer pattern comes first, and the patterns of same length are sorted
+// in lexical order.
+func matchRepoGlobs(repoToTokenMap map[string]hmacsForRepo, repo string) []hmacLevel {
+	var patterns []string
+	for k := range repoToTokenMap {
+		if k == repo || !strings.Contains(k, "/") || !strings.ContainsAny(k, "*?[") {
+			continue
+		}
+
+		if ok, err := path.Match(k, repo); err == nil && ok {
+			patterns = append(patterns, k)
+		}
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+
+		return patterns[i] < patterns[j]
+	})
+
+	levels := make([]hmacLevel, len(patterns))
+	for i, p := range patterns {
+		levels[i] = hmacLevel{p, HmacLevelRepoGlob}
 	}
 
-	if val, ok := repoToTokenMap[orgName]; ok {
-		return extractTokens(val), nil
+	return levels
+}
+
+// filterExpiredTokens returns the tokens which are not older than maxAge.
+func filterExpiredTokens(allTokens hmacsForRepo, maxAge time.Duration) hmacsForRepo {
+	if maxAge <= 0 {
+		return allTokens
 	}
 
-	if val, ok := repoToTokenMap["*"]; ok {
-		return extractTokens(val), nil
+	deadline := time.Now().Add(-maxAge)
+
+	validTokens := make(hmacsForRepo, 0, len(allTokens))
+	for i := range allTokens {
+		if t := allTokens[i].CreatedAt; t.IsZero() || t.After(deadline) {
+			validTokens = append(validTokens, allTokens[i])
+		}
 	}
 
-	return nil, errors.New("invalid content in secret file, global token doesn't exist")
+	return validTokens
 }
 
 // extractTokens return tokens for any given level of tree.
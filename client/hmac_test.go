@@ -0,0 +1,395 @@
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestHmacSecretCache(t *testing.T) {
+	cases := []struct {
+		name    string
+		size    int
+		files   []string
+		cached  []string
+		evicted []string
+	}{
+		{
+			name:   "same content is parsed once",
+			size:   2,
+			files:  []string{`"*": [{value: a}]`, `"*": [{value: a}]`},
+			cached: []string{`"*": [{value: a}]`},
+		},
+		{
+			name:   "files of generators are kept together",
+			size:   2,
+			files:  []string{`"*": [{value: a}]`, `"*": [{value: b}]`, `"*": [{value: a}]`},
+			cached: []string{`"*": [{value: a}]`, `"*": [{value: b}]`},
+		},
+		{
+			name:    "least recently used is evicted",
+			size:    2,
+			files:   []string{`"*": [{value: a}]`, `"*": [{value: b}]`, `"*": [{value: a}]`, `"*": [{value: c}]`},
+			cached:  []string{`"*": [{value: a}]`, `"*": [{value: c}]`},
+			evicted: []string{`"*": [{value: b}]`},
+		},
+		{
+			name:   "invalid file is cached with its error",
+			size:   2,
+			files:  []string{`[`},
+			cached: []string{`[`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cache := newHmacSecretCache(c.size)
+
+			for _, f := range c.files {
+				_, _ = cache.parse([]byte(f))
+			}
+
+			if n := cache.lru.Len(); n != len(c.cached) {
+				t.Errorf("got %d cached files, want %d", n, len(c.cached))
+			}
+
+			for _, f := range c.cached {
+				if !cache.has([]byte(f)) {
+					t.Errorf("%q is not cached", f)
+				}
+			}
+
+			for _, f := range c.evicted {
+				if cache.has([]byte(f)) {
+					t.Errorf("%q is not evicted", f)
+				}
+			}
+		})
+	}
+}
+
+func TestHmacSecretCacheReturnsParsedTokens(t *testing.T) {
+	cache := newHmacSecretCache(defaultSecretCacheSize)
+
+	for _, want := range []string{"a", "b", "a"} {
+		tokens, err := cache.parse([]byte(fmt.Sprintf(`"*": [{value: %s}]`, want)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := tokens["*"]; len(got) != 1 || got[0].Value != want {
+			t.Errorf("got tokens %v, want %s", got, want)
+		}
+	}
+}
+
+// has tells whether the content is cached without touching the order of lru.
+func (c *hmacSecretCache) has(t []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.items[sha256.Sum256(t)]
+
+	return ok
+}
+
+func TestExtractHmacsFiltersExpiredTokens(t *testing.T) {
+	now := time.Now()
+	file := map[string]hmacsForRepo{
+		"owner/repo": {{Value: "old", CreatedAt: now.Add(-48 * time.Hour)}},
+		"owner": {
+			{Value: "old", CreatedAt: now.Add(-48 * time.Hour)},
+			{Value: "fresh", CreatedAt: now.Add(-time.Hour)},
+			{Value: "forever"},
+		},
+		"*": {{Value: "global"}},
+	}
+
+	b, err := yaml.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		maxAge time.Duration
+		level  string
+		tokens []string
+	}{
+		{name: "no max age", level: HmacLevelRepo, tokens: []string{"old"}},
+		{name: "falls back to org", maxAge: 24 * time.Hour, level: HmacLevelOrg, tokens: []string{"fresh", "forever"}},
+		{name: "keeps the tokens without time", maxAge: time.Minute, level: HmacLevelOrg, tokens: []string{"forever"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			level, secrets, err := extractHmacs(
+				"owner/repo", "", "", b,
+				newHmacSecretCache(defaultSecretCacheSize), c.maxAge, defaultLogger,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if level != c.level {
+				t.Errorf("got level %s, want %s", level, c.level)
+			}
+
+			if got := tokenValues(secrets); fmt.Sprint(got) != fmt.Sprint(c.tokens) {
+				t.Errorf("got tokens %v, want %v", got, c.tokens)
+			}
+		})
+	}
+}
+
+func TestExtractHmacsLookupOrder(t *testing.T) {
+	cases := []struct {
+		name      string
+		keys      []string
+		eventType string
+		level     string
+		token     string
+	}{
+		{name: "repo:event beats repo", keys: []string{"owner/repo:push", "owner/repo"}, eventType: "push", level: HmacLevelRepoEvent, token: "owner/repo:push"},
+		{name: "repo beats glob", keys: []string{"owner/repo", "owner/*"}, eventType: "push", level: HmacLevelRepo, token: "owner/repo"},
+		{name: "glob beats org:event", keys: []string{"owner/*", "owner:push"}, eventType: "push", level: HmacLevelRepoGlob, token: "owner/*"},
+		{name: "org:event beats org", keys: []string{"owner:push", "owner"}, eventType: "push", level: HmacLevelOrgEvent, token: "owner:push"},
+		{name: "org beats installation", keys: []string{"owner", "installation:1"}, eventType: "push", level: HmacLevelOrg, token: "owner"},
+		{name: "installation beats global", keys: []string{"installation:1", "*"}, eventType: "push", level: HmacLevelInstallation, token: "installation:1"},
+		{name: "global", keys: []string{"*"}, eventType: "push", level: HmacLevelGlobal, token: "*"},
+		{
+			name: "repo without the key of event", keys: []string{"owner/repo:pull_request", "owner/repo"},
+			eventType: "push", level: HmacLevelRepo, token: "owner/repo",
+		},
+		{
+			name: "org without the key of event", keys: []string{"owner:pull_request", "owner"},
+			eventType: "push", level: HmacLevelOrg, token: "owner",
+		},
+		{
+			name: "the key of event is skipped without event type", keys: []string{"owner/repo:push", "owner/repo"},
+			level: HmacLevelRepo, token: "owner/repo",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := map[string]hmacsForRepo{}
+			for _, k := range c.keys {
+				file[k] = hmacsForRepo{{Value: k}}
+			}
+
+			b, err := yaml.Marshal(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			level, secrets, err := extractHmacs(
+				"owner/repo", "installation:1", c.eventType, b,
+				newHmacSecretCache(defaultSecretCacheSize), 0, defaultLogger,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if level != c.level {
+				t.Errorf("got level %s, want %s", level, c.level)
+			}
+
+			if got := tokenValues(secrets); fmt.Sprint(got) != fmt.Sprint([]string{c.token}) {
+				t.Errorf("got tokens %v, want %s", got, c.token)
+			}
+		})
+	}
+}
+
+func TestMatchRepoGlobs(t *testing.T) {
+	file := map[string]hmacsForRepo{}
+	for _, k := range []string{
+		"owner/*", "owner/repo-*", "owner/rep?-*", "*/repo-1", "owner/[a-z]*",
+		// They are not the globs matching the repo.
+		"owner/repo-1", "owner", "owner:push", "other/*", "owner/lib-*",
+	} {
+		file[k] = hmacsForRepo{{Value: k}}
+	}
+
+	// The longer pattern comes first, and the ones of same length are in lexical order.
+	want := []string{"owner/[a-z]*", "owner/rep?-*", "owner/repo-*", "*/repo-1", "owner/*"}
+
+	// The order doesn't depend on the iteration of map.
+	for i := 0; i < 20; i++ {
+		levels := matchRepoGlobs(file, "owner/repo-1")
+
+		got := make([]string, len(levels))
+		for j := range levels {
+			got[j] = levels[j].key
+
+			if levels[j].level != HmacLevelRepoGlob {
+				t.Fatalf("got level %s of %s", levels[j].level, levels[j].key)
+			}
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("got globs %v, want %v", got, want)
+		}
+	}
+
+	delete(file, "owner/repo-1")
+
+	b, err := yaml.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	level, secrets, err := extractHmacs("owner/repo-1", "", "", b, newHmacSecretCache(defaultSecretCacheSize), 0, defaultLogger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if level != HmacLevelRepoGlob || secrets[0].Value != want[0] {
+		t.Errorf("got tokens %v of %s, want the ones of %s", tokenValues(secrets), level, want[0])
+	}
+}
+
+func TestValidateEventWithoutRepository(t *testing.T) {
+	file := []byte(`
+"owner": [{value: org}]
+"installation:42": [{value: installation}]
+"*": [{value: global}]
+`)
+
+	cases := []struct {
+		name    string
+		payload string
+		token   string
+		level   string
+	}{
+		{
+			name:    "ping",
+			payload: `{"zen":"Keep it logically awesome.","hook_id":1}`,
+			token:   "global",
+			level:   HmacLevelGlobal,
+		},
+		{
+			name:    "organization",
+			payload: `{"action":"member_added","organization":{"login":"owner"}}`,
+			token:   "org",
+			level:   HmacLevelOrg,
+		},
+		{
+			name:    "installation",
+			payload: `{"action":"created","installation":{"id":42,"account":{"login":"someone"}}}`,
+			token:   "installation",
+			level:   HmacLevelInstallation,
+		},
+		{
+			name:    "installation without its own tokens",
+			payload: `{"action":"created","installation":{"id":43,"account":{"login":"someone"}}}`,
+			token:   "global",
+			level:   HmacLevelGlobal,
+		},
+	}
+
+	v := NewValidator(WithTokenGenerator(func() []byte { return file }))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payload := []byte(c.payload)
+
+			m, err := v.ValidateEvent("", payload, PayloadSignature256(payload, []byte(c.token)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if m.Level != c.level {
+				t.Errorf("got level %s, want %s", m.Level, c.level)
+			}
+		})
+	}
+}
+
+func tokenValues(secrets hmacsForRepo) []string {
+	r := make([]string, len(secrets))
+	for i := range secrets {
+		r[i] = secrets[i].Value
+	}
+
+	return r
+}
+
+func benchmarkSecretFile() []byte {
+	file := map[string]hmacsForRepo{}
+	for i := 0; i < 100; i++ {
+		file[fmt.Sprintf("owner/repo-%d", i)] = hmacsForRepo{{Value: fmt.Sprintf("token-%d", i)}}
+	}
+
+	b, err := yaml.Marshal(file)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func BenchmarkHmacSecretCacheParse(b *testing.B) {
+	t := benchmarkSecretFile()
+	cache := newHmacSecretCache(defaultSecretCacheSize)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.parse(t); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValidatePayloadSharesSecretCache(t *testing.T) {
+	f := benchmarkSecretFile()
+	gen := func() []byte { return f }
+	payload := []byte(`{"repository":{"full_name":"owner/repo-1"}}`)
+
+	if newDefaultValidator(gen).secrets != newDefaultValidator(gen).secrets {
+		t.Fatal("the package level validators don't share the secret cache")
+	}
+
+	for i := 0; i < 2; i++ {
+		if !ValidatePayload(payload, PayloadSignature256(payload, []byte("token-1")), gen) {
+			t.Fatalf("the validation %d failed", i)
+		}
+
+		if !defaultSecrets.has(f) {
+			t.Fatalf("the secret file is not cached after the validation %d", i)
+		}
+	}
+}
+
+func BenchmarkValidatePayload(b *testing.B) {
+	f := benchmarkSecretFile()
+	gen := func() []byte { return f }
+	payload := []byte(`{"repository":{"full_name":"owner/repo-1"}}`)
+	sig := PayloadSignature256(payload, []byte("token-1"))
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if !ValidatePayload(payload, sig, gen) {
+			b.Fatal("the validation failed")
+		}
+	}
+}
+
+func BenchmarkYamlUnmarshalSecretFile(b *testing.B) {
+	t := benchmarkSecretFile()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		tokens := map[string]hmacsForRepo{}
+		if err := yaml.Unmarshal(t, &tokens); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// The helpers of webhooks below manage the hooks of repo, or the hooks of org
+// if the repo is empty.
+
+// ListHooks returns all the webhooks of the repo or org.
+func (cl client) ListHooks(org, repo string) ([]*sdk.Hook, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var hooks []*sdk.Hook
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		var v []*sdk.Hook
+		var resp *sdk.Response
+		var err error
+
+		if repo == "" {
+			v, resp, err = cl.c.Organizations.ListHooks(ctx, org, opt)
+		} else {
+			v, resp, err = cl.c.Repositories.ListHooks(ctx, org, repo, opt)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list hooks of %s: %w", hookOwner(org, repo), err)
+		}
+
+		hooks = append(hooks, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return hooks, nil
+}
+
+// CreateHook creates an active webhook which delivers the events to hookURL in
+// json and is signed by secret. If there is a webhook of the same url, it is
+// returned instead of creating a duplicate one, and it is not updated.
+func (cl client) CreateHook(org, repo, hookURL, secret string, events []string) (*sdk.Hook, error) {
+	hooks, err := cl.ListHooks(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hooks {
+		if u, ok := h.Config["url"].(string); ok && u == hookURL {
+			return h, nil
+		}
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	hook := &sdk.Hook{
+		Events: events,
+		Active: sdk.Bool(true),
+		Config: map[string]interface{}{
+			"url":          hookURL,
+			"content_type": "json",
+			"secret":       secret,
+			"insecure_ssl": "0",
+		},
+	}
+
+	var v *sdk.Hook
+	if repo == "" {
+		v, _, err = cl.c.Organizations.CreateHook(ctx, org, hook)
+	} else {
+		v, _, err = cl.c.Repositories.CreateHook(ctx, org, repo, hook)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hook of %s: %w", hookOwner(org, repo), err)
+	}
+
+	return v, nil
+}
+
+// DeleteHook deletes the webhook.
+func (cl client) DeleteHook(org, repo string, id int64) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var err error
+	if repo == "" {
+		_, err = cl.c.Organizations.DeleteHook(ctx, org, id)
+	} else {
+		_, err = cl.c.Repositories.DeleteHook(ctx, org, repo, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete hook %d of %s: %w", id, hookOwner(org, repo), err)
+	}
+
+	return nil
+}
+
+// PingHook makes github send a ping event to the webhook.
+func (cl client) PingHook(org, repo string, id int64) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var err error
+	if repo == "" {
+		_, err = cl.c.Organizations.PingHook(ctx, org, id)
+	} else {
+		_, err = cl.c.Repositories.PingHook(ctx, org, repo, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to ping hook %d of %s: %w", id, hookOwner(org, repo), err)
+	}
+
+	return nil
+}
+
+func hookOwner(org, repo string) string {
+	if repo == "" {
+		return org
+	}
+
+	return org + "/" + repo
+}
@@ -1,7 +1,11 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"time"
 
 	sdk "github.com/google/go-github/v36/github"
 )
@@ -16,7 +20,9 @@ func (p PRInfo) String() string {
 	return fmt.Sprintf("%s/%s:%d", p.Org, p.Repo, p.Number)
 }
 
-// Client interface for GitHub API
+// Client interface for GitHub API. It is implemented by the client returned by
+// NewClient, and the bots should depend on it, so that they can be tested with
+// a mock such as clienttest.FakeClient.
 type Client interface {
 	AddPRLabel(pr PRInfo, label string) error
 	RemovePRLabel(pr PRInfo, label string) error
@@ -72,4 +78,99 @@ type Client interface {
 	GetSinglePR(org, repo string, number int) (*sdk.PullRequest, error)
 	GetBot() (string, error)
 	ListOrg() ([]string, error)
+	CreateComment(org, repo string, number int, comment string) error
+	ListPullRequestFiles(org, repo string, number int) ([]*sdk.CommitFile, error)
+	AddLabel(org, repo string, number int, label string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	ReplaceLabels(org, repo string, number int, labels []string) error
+	RateLimits() (*sdk.RateLimits, error)
+	RemainingCore() (int, time.Time, error)
+	LastRate() (sdk.Rate, bool)
+	CreatePullRequest(org, repo, title, body, head, base string, opt *CreatePullRequestOptions) (*sdk.PullRequest, error)
+	UpdatePullRequest(org, repo string, number int, opt UpdatePullRequestOptions) (*sdk.PullRequest, error)
+	UpdatePullRequestBranch(org, repo string, number int, expectedHeadSHA string) error
+	ListPullRequests(org, repo string, opts PRListOptions) ([]*sdk.PullRequest, error)
+	SearchIssues(query string, opts SearchOptions) (IssueSearchResult, error)
+	SearchCode(query string, opts SearchOptions) (CodeSearchResult, error)
+	CloseIssueWithReason(org, repo string, number int, reason string) error
+	DeleteStaleBotComments(org, repo string, number int, botLogin, marker string) (int, error)
+	UpsertComment(org, repo string, number int, marker, body string) error
+	SetMilestone(org, repo string, number int, milestoneNumber int) error
+	ClearMilestone(org, repo string, number int) error
+	ListMilestones(org, repo string) ([]*sdk.Milestone, error)
+	CreateRelease(org, repo string, rel *sdk.RepositoryRelease) (*sdk.RepositoryRelease, error)
+	UploadReleaseAsset(org, repo string, releaseID int64, name string, r io.Reader) error
+	CompareCommits(org, repo, base, head string) (*sdk.CommitsComparison, error)
+	ListOrgRepos(org string, opts RepoListOptions) ([]*sdk.Repository, error)
+	GetHookIPRanges() ([]string, error)
+	CreateDeployment(org, repo string, req *sdk.DeploymentRequest) (*sdk.Deployment, error)
+	CreateDeploymentStatus(org, repo string, deploymentID int64, req *sdk.DeploymentStatusRequest) (*sdk.DeploymentStatus, error)
+	FindPullRequestsForCommit(org, repo, sha string) ([]*sdk.PullRequest, error)
+	ListProjectColumns(projectID int64) ([]*sdk.ProjectColumn, error)
+	CreateProjectCard(columnID int64, opt *sdk.ProjectCardOptions) (*sdk.ProjectCard, error)
+	MoveProjectCard(cardID, columnID int64, position string) error
+	ListHooks(org, repo string) ([]*sdk.Hook, error)
+	CreateHook(org, repo, hookURL, secret string, events []string) (*sdk.Hook, error)
+	DeleteHook(org, repo string, id int64) error
+	PingHook(org, repo string, id int64) error
+	GetCombinedState(org, repo, ref string) (string, []ContextResult, error)
+	BlockUser(org, user string) error
+	UnblockUser(org, user string) error
+	ListBlockedUsers(org string) ([]*sdk.User, error)
+	GetCommentNodeID(org, repo string, commentID int64) (string, error)
+	GetReviewCommentNodeID(org, repo string, commentID int64) (string, error)
+	WithCallOptions(opts ...CallOption) Client
+	GetReviewSummary(org, repo string, number int) (approvals []string, changesRequested []string, err error)
+	GetTeamMembership(org, teamSlug, user string) (string, error)
+	IsTeamMember(org, teamSlug, user string) (bool, error)
+	ListTeamRepos(org, teamSlug string) ([]*sdk.Repository, error)
+	ListIssuesPage(org, repo string, opts *sdk.IssueListByRepoOptions) ([]*sdk.Issue, int, error)
+	ListCommentsPage(org, repo string, number int, opts *sdk.IssueListCommentsOptions) ([]*sdk.IssueComment, int, error)
+	ListPullRequestsPage(org, repo string, opts *sdk.PullRequestListOptions) ([]*sdk.PullRequest, int, error)
+	CreateOrUpdateRepoSecret(org, repo, name, plaintext string) error
+	ListRepoSecrets(org, repo string) ([]*sdk.Secret, error)
+	CreateOrUpdateRepoVariable(org, repo, name, value string) error
+	ListRepoVariables(org, repo string) ([]ActionsVariable, error)
+	ListCheckSuitesForRef(org, repo, ref string) ([]*sdk.CheckSuite, error)
+	RerequestCheckSuite(org, repo string, checkSuiteID int64) error
+	RerequestCheckRun(org, repo string, checkRunID int64) error
+	RerequestFailedCheckSuites(org, repo, ref string) ([]int64, error)
+	GetDefaultBranch(org, repo string) (string, error)
+	GetLatestCommit(org, repo, branch string) (*sdk.RepositoryCommit, error)
+	WaitForRateLimit(ctx context.Context) error
+	ListPullRequestCommits(org, repo string, number int) ([]*sdk.RepositoryCommit, error)
+	CreateRepositoryDispatch(org, repo, eventType string, payload json.RawMessage) error
+	CreateWorkflowDispatch(org, repo, workflowFileName, ref string, inputs map[string]interface{}) error
+	LockIssue(org, repo string, number int, reason string) error
+	UnlockIssue(org, repo string, number int) error
+	ListInstallationRepos() ([]*sdk.Repository, error)
+	ListIssueTimeline(org, repo string, number int) ([]*sdk.Timeline, error)
+	ForEachIssueTimeline(org, repo string, number int, fn func(*sdk.Timeline) error) error
+	GetRepoTopics(org, repo string) ([]string, error)
+	SetRepoTopics(org, repo string, topics []string) error
+	AddRepoTopics(org, repo string, topics []string) error
+	MergeabilityState(ctx context.Context, org, repo string, number int) (mergeable *bool, mergeState string, err error)
+	SetStatuses(org, repo, sha string, statuses []sdk.RepoStatus) error
+	CreateGist(description string, public bool, files map[string]string) (*sdk.Gist, error)
+	GetGist(id string) (*sdk.Gist, error)
+	UpdateGist(id, description string, files map[string]GistFileUpdate) (*sdk.Gist, error)
+	Merge(org, repo string, number int, method, sha string) error
+	MergeWithMessage(org, repo string, number int, method, sha, title, message string) error
+	CreateStatus(org, repo, sha string, status sdk.RepoStatus) error
+	SetCheckRun(org, repo, sha string, opt CheckRunOptions) (*sdk.CheckRun, error)
+	GetFileContent(org, repo, path, ref string) ([]byte, error)
+	CreateCommentReaction(org, repo string, commentID int64, content string) error
+	CreateIssueReaction(org, repo string, number int, content string) error
+	CreateReviewCommentReaction(org, repo string, commentID int64, content string) error
+	AssignIssue(org, repo string, number int, logins []string) ([]string, error)
+	UnassignIssue(org, repo string, number int, logins []string) error
+	RequestReviewers(org, repo string, number int, users, teams []string) ([]string, []string, error)
+	RemoveRequestedReviewers(org, repo string, number int, users, teams []string) error
+	GetUserPermission(org, repo, user string) (string, error)
+	IsRepoCollaborator(org, repo, user string) (bool, error)
+	GetBranchProtection(org, repo, branch string) (*sdk.Protection, error)
+	UpdateBranchProtection(org, repo, branch string, req *sdk.ProtectionRequest) error
+	PatchBranchProtection(org, repo, branch string, mutate func(*sdk.ProtectionRequest)) error
 }
+
+var _ Client = client{}
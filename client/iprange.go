@@ -0,0 +1,175 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultIPRangesRefresh is the default interval of refreshing the hook ip ranges.
+const DefaultIPRangesRefresh = time.Hour
+
+// ipRangesRetryInterval is the least interval of fetching the hook ip ranges
+// after a failure, so that github is not called on every check when it is down.
+const ipRangesRetryInterval = time.Minute
+
+// GetHookIPRanges returns the CIDR ranges from which github sends the webhooks.
+// It targets the GitHub Enterprise Server if the client does, see WithBaseURL.
+func (cl client) GetHookIPRanges() ([]string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	meta, _, err := cl.c.APIMeta(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the meta: %w", err)
+	}
+
+	return meta.Hooks, nil
+}
+
+// IPAllowlistOption configures the IPAllowlist.
+type IPAllowlistOption func(*IPAllowlist)
+
+// WithRefreshInterval sets how often the ip ranges are refreshed. See DefaultIPRangesRefresh.
+func WithRefreshInterval(d time.Duration) IPAllowlistOption {
+	return func(l *IPAllowlist) {
+		if d > 0 {
+			l.refresh = d
+		}
+	}
+}
+
+// WithFailOpen makes Check allow all the addresses when the ip ranges have never
+// been fetched successfully. By default, all of them are rejected.
+func WithFailOpen() IPAllowlistOption {
+	return func(l *IPAllowlist) {
+		l.failOpen = true
+	}
+}
+
+// IPAllowlist checks whether the webhook comes from the hook ip ranges of github.
+// It is a defense in depth, and the payload should still be validated with hmac.
+type IPAllowlist struct {
+	cli      Client
+	refresh  time.Duration
+	failOpen bool
+
+	lock      sync.Mutex
+	nets      []*net.IPNet
+	fetched   bool
+	fetchedAt time.Time
+	failedAt  time.Time
+	fetching  bool
+}
+
+// NewIPAllowlist returns an IPAllowlist fetching the ip ranges by the client.
+// The ip ranges are fetched lazily, and the last ones are kept if refreshing fails.
+// After a failure, they are fetched again at most once a minute.
+func NewIPAllowlist(cli Client, opts ...IPAllowlistOption) *IPAllowlist {
+	l := &IPAllowlist{
+		cli:     cli,
+		refresh: DefaultIPRangesRefresh,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Check tells whether the remote address, such as http.Request.RemoteAddr,
+// is in the hook ip ranges. The address can be with or without the port.
+func (l *IPAllowlist) Check(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+
+	nets, ok := l.get()
+	if !ok {
+		return l.failOpen
+	}
+
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get returns the ip ranges, and refreshes them if they are stale. It returns
+// false if they have never been fetched successfully. Only one check fetches
+// them at a time, and the others don't wait for it but use the current ones.
+func (l *IPAllowlist) get() ([]*net.IPNet, bool) {
+	l.lock.Lock()
+	if !l.shouldFetch(time.Now()) {
+		defer l.lock.Unlock()
+
+		return l.nets, l.fetched
+	}
+
+	l.fetching = true
+	l.lock.Unlock()
+
+	nets, err := l.fetch()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.fetching = false
+
+	if err != nil {
+		defaultLogger.Error("failed to refresh the hook ip ranges", LogFields{"error": err.Error()})
+
+		l.failedAt = time.Now()
+
+		return l.nets, l.fetched
+	}
+
+	l.nets = nets
+	l.fetched = true
+	l.fetchedAt = time.Now()
+	l.failedAt = time.Time{}
+
+	return nets, true
+}
+
+// shouldFetch tells whether the ip ranges should be fetched at now. They are
+// not fetched until the refresh interval passes since the last success, nor
+// until the retry interval passes since the last failure.
+func (l *IPAllowlist) shouldFetch(now time.Time) bool {
+	if l.fetching || (l.fetched && now.Sub(l.fetchedAt) < l.refresh) {
+		return false
+	}
+
+	return l.failedAt.IsZero() || now.Sub(l.failedAt) >= ipRangesRetryInterval
+}
+
+func (l *IPAllowlist) fetch() ([]*net.IPNet, error) {
+	ranges, err := l.cli.GetHookIPRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	nets := make([]*net.IPNet, 0, len(ranges))
+	for _, v := range ranges {
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip range %q: %v", v, err)
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIPAllowlistCheck(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"hooks":["192.30.252.0/22","2a0a:a440::/29"]}`)
+	})
+
+	l := NewIPAllowlist(s.client(t))
+
+	cases := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "192.30.252.1:443", want: true},
+		{addr: "192.30.252.1", want: true},
+		{addr: "[2a0a:a440::1]:80", want: true},
+		{addr: "2a0a:a440::1", want: true},
+		{addr: "10.0.0.1:80"},
+		{addr: "[::1]:80"},
+		{addr: "not an ip"},
+		{addr: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.addr, func(t *testing.T) {
+			if got := l.Check(c.addr); got != c.want {
+				t.Errorf("got %t, want %t", got, c.want)
+			}
+		})
+	}
+
+	if got := s.received(); len(got) != 1 {
+		t.Errorf("got requests %v, want only one", got)
+	}
+}
+
+func TestIPAllowlistFailure(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  []IPAllowlistOption
+		fetch bool
+		want  bool
+	}{
+		{name: "fail closed"},
+		{name: "fail open", opts: []IPAllowlistOption{WithFailOpen()}, want: true},
+		{name: "keep the last ranges", fetch: true, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			down := !c.fetch
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if down {
+					w.WriteHeader(http.StatusInternalServerError)
+
+					return
+				}
+
+				fmt.Fprint(w, `{"hooks":["192.30.252.0/22"]}`)
+			})
+
+			l := NewIPAllowlist(s.client(t), append(c.opts, WithRefreshInterval(time.Nanosecond))...)
+			if c.fetch {
+				l.Check("192.30.252.1")
+				down = true
+				time.Sleep(time.Millisecond)
+			}
+
+			for i := 0; i < 3; i++ {
+				if got := l.Check("192.30.252.1"); got != c.want {
+					t.Errorf("got %t of check %d, want %t", got, i, c.want)
+				}
+			}
+
+			// The failed fetch is not retried until the retry interval passes.
+			want := 1
+			if c.fetch {
+				want = 2
+			}
+
+			if got := s.received(); len(got) != want {
+				t.Errorf("got requests %v, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestIPAllowlistShouldFetch(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		l    *IPAllowlist
+		want bool
+	}{
+		{name: "never fetched", l: &IPAllowlist{refresh: time.Hour}, want: true},
+		{name: "fresh", l: &IPAllowlist{refresh: time.Hour, fetched: true, fetchedAt: now}},
+		{
+			name: "stale",
+			l:    &IPAllowlist{refresh: time.Hour, fetched: true, fetchedAt: now.Add(-2 * time.Hour)},
+			want: true,
+		},
+		{
+			name: "failed just now",
+			l:    &IPAllowlist{refresh: time.Hour, fetched: true, fetchedAt: now.Add(-2 * time.Hour), failedAt: now.Add(-time.Second)},
+		},
+		{name: "never fetched and failed just now", l: &IPAllowlist{refresh: time.Hour, failedAt: now.Add(-time.Second)}},
+		{
+			name: "failed a while ago",
+			l:    &IPAllowlist{refresh: time.Hour, failedAt: now.Add(-ipRangesRetryInterval)},
+			want: true,
+		},
+		{name: "being fetched", l: &IPAllowlist{refresh: time.Hour, fetching: true}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.l.shouldFetch(now); got != c.want {
+				t.Errorf("got %t, want %t", got, c.want)
+			}
+		})
+	}
+}
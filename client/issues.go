@@ -0,0 +1,210 @@
+package client
+
+import (
+	"fmt"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+const (
+	// StateReasonCompleted means the issue is closed because it is done.
+	StateReasonCompleted = "completed"
+	// StateReasonNotPlanned means the issue is closed because it won't be done,
+	// for example it is stale or duplicate.
+	StateReasonNotPlanned = "not_planned"
+)
+
+// issueStateRequest is the request of editing the state of issue. The state_reason
+// field is not supported by the sdk yet.
+type issueStateRequest struct {
+	State       string `json:"state"`
+	StateReason string `json:"state_reason,omitempty"`
+}
+
+// CloseIssueWithReason closes the issue or pull request with the state reason
+// which is one of "completed" and "not_planned". Empty reason means "completed".
+// It is ok to close an issue which has been closed. Use ReopenIssue to reopen it.
+func (cl client) CloseIssueWithReason(org, repo string, number int, reason string) error {
+	switch reason {
+	case "", StateReasonCompleted, StateReasonNotPlanned:
+	default:
+		return fmt.Errorf("unknown state reason: %s", reason)
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	u := fmt.Sprintf("repos/%s/%s/issues/%d", org, repo, number)
+	req, err := cl.c.NewRequest("PATCH", u, &issueStateRequest{State: ActionClosed, StateReason: reason})
+	if err != nil {
+		return err
+	}
+
+	if _, err := cl.c.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("failed to close issue %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// ListIssuesPage returns a page of the issues of repo and the number of next
+// page which is zero if it is the last page, so that a large listing can be
+// processed page by page and resumed from the next page. Set opts.Page to the
+// page to fetch. Note that the pull requests are listed as issues as well.
+func (cl client) ListIssuesPage(org, repo string, opts *sdk.IssueListByRepoOptions) ([]*sdk.Issue, int, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, resp, err := cl.c.Issues.ListByRepo(ctx, org, repo, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list issues of %s/%s: %w", org, repo, err)
+	}
+
+	page, err := nextPage(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, page, nil
+}
+
+// ListCommentsPage is the same as ListIssuesPage except that it returns a page
+// of the comments of issue or pull request.
+func (cl client) ListCommentsPage(
+	org, repo string, number int, opts *sdk.IssueListCommentsOptions,
+) ([]*sdk.IssueComment, int, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, resp, err := cl.c.Issues.ListComments(ctx, org, repo, number, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list comments of %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	page, err := nextPage(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, page, nil
+}
+
+// ListPullRequestsPage is the same as ListIssuesPage except that it returns
+// a page of the pull requests of repo.
+func (cl client) ListPullRequestsPage(
+	org, repo string, opts *sdk.PullRequestListOptions,
+) ([]*sdk.PullRequest, int, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, resp, err := cl.c.PullRequests.List(ctx, org, repo, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list pull requests of %s/%s: %w", org, repo, err)
+	}
+
+	page, err := nextPage(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, page, nil
+}
+
+const (
+	// LockReasonOffTopic means the conversation is off topic.
+	LockReasonOffTopic = "off-topic"
+	// LockReasonTooHeated means the conversation is too heated.
+	LockReasonTooHeated = "too heated"
+	// LockReasonResolved means the conversation is resolved.
+	LockReasonResolved = "resolved"
+	// LockReasonSpam means the conversation is spam.
+	LockReasonSpam = "spam"
+)
+
+// LockIssue locks the conversation of issue or pull request, so that only the
+// collaborators can comment. The reason is one of "off-topic", "too heated",
+// "resolved" and "spam", and it can be empty. It is ok to lock an issue which
+// has been locked.
+func (cl client) LockIssue(org, repo string, number int, reason string) error {
+	switch reason {
+	case "", LockReasonOffTopic, LockReasonTooHeated, LockReasonResolved, LockReasonSpam:
+	default:
+		return fmt.Errorf("unknown lock reason: %s", reason)
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var opt *sdk.LockIssueOptions
+	if reason != "" {
+		opt = &sdk.LockIssueOptions{LockReason: reason}
+	}
+
+	if _, err := cl.c.Issues.Lock(ctx, org, repo, number, opt); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// UnlockIssue unlocks the conversation of issue or pull request.
+func (cl client) UnlockIssue(org, repo string, number int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, err := cl.c.Issues.Unlock(ctx, org, repo, number); err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// ListIssueTimeline returns all the timeline events of issue or pull request,
+// such as the labels, assignees, reviews and cross references, which tell who
+// did what when. See ForEachIssueTimeline for the very active issues.
+func (cl client) ListIssueTimeline(org, repo string, number int) ([]*sdk.Timeline, error) {
+	var events []*sdk.Timeline
+
+	err := cl.ForEachIssueTimeline(org, repo, number, func(e *sdk.Timeline) error {
+		events = append(events, e)
+
+		return nil
+	})
+
+	return events, err
+}
+
+// ForEachIssueTimeline calls fn with each timeline event of issue or pull request
+// in order, without holding all of them in memory. It stops and returns the error
+// if fn returns one. Each page has its own timeout, so that fn doesn't consume it.
+func (cl client) ForEachIssueTimeline(org, repo string, number int, fn func(*sdk.Timeline) error) error {
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		ctx, cancel := cl.newContext()
+		v, resp, err := cl.c.Issues.ListIssueTimeline(ctx, org, repo, number, opt)
+		cancel()
+
+		if err != nil {
+			return fmt.Errorf("failed to list timeline of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		for _, e := range v {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestIssueStateReason(t *testing.T) {
+	cases := []struct {
+		name            string
+		call            func(Client) error
+		wantState       string
+		wantStateReason string
+		wantErr         bool
+	}{
+		{
+			name:            "completed",
+			call:            func(c Client) error { return c.CloseIssueWithReason("owner", "repo", 1, StateReasonCompleted) },
+			wantState:       "closed",
+			wantStateReason: "completed",
+		},
+		{
+			name:            "not planned",
+			call:            func(c Client) error { return c.CloseIssueWithReason("owner", "repo", 1, StateReasonNotPlanned) },
+			wantState:       "closed",
+			wantStateReason: "not_planned",
+		},
+		{
+			name:      "default reason",
+			call:      func(c Client) error { return c.CloseIssueWithReason("owner", "repo", 1, "") },
+			wantState: "closed",
+		},
+		{
+			name:      "reopen",
+			call:      func(c Client) error { return c.ReopenIssue(PRInfo{Org: "owner", Repo: "repo", Number: 1}) },
+			wantState: "open",
+		},
+		{
+			name:    "unknown reason",
+			call:    func(c Client) error { return c.CloseIssueWithReason("owner", "repo", 1, "stale") },
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"number":1}`)
+			})
+
+			err := c.call(s.client(t))
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				if got := s.received(); len(got) != 0 {
+					t.Errorf("got requests %v, want none", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got, want := s.received(), []string{"PATCH /repos/owner/repo/issues/1"}; fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("got requests %v, want %v", got, want)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(s.bodies[0]), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body["state"] != c.wantState {
+				t.Errorf("got state %v, want %s", body["state"], c.wantState)
+			}
+
+			reason, ok := body["state_reason"]
+			if c.wantStateReason == "" {
+				if ok {
+					t.Errorf("got state reason %v, want none", reason)
+				}
+			} else if reason != c.wantStateReason {
+				t.Errorf("got state reason %v, want %s", reason, c.wantStateReason)
+			}
+		})
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package client
 
 import (
+	"fmt"
+	"net/url"
 	"regexp"
+	"strconv"
+
+	sdk "github.com/google/go-github/v36/github"
 )
 
 var lre = regexp.MustCompile(`<([^>]*)>; *rel="([^"]*)"`)
@@ -35,3 +40,24 @@ func parseLinks(h string) map[string]string {
 	}
 	return links
 }
+
+// nextPage returns the number of next page parsed from the Link header of
+// the response. It returns 0 if there is no next page.
+func nextPage(resp *sdk.Response) (int, error) {
+	link := parseLinks(resp.Header.Get("Link"))["next"]
+	if link == "" {
+		return 0, nil
+	}
+
+	pagePath, err := url.Parse(link)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse 'next' link: %v", err)
+	}
+
+	p := pagePath.Query().Get("page")
+	if p == "" {
+		return 0, fmt.Errorf("failed to get 'page' on link: %s", link)
+	}
+
+	return strconv.Atoi(p)
+}
@@ -0,0 +1,38 @@
+package client
+
+import "github.com/sirupsen/logrus"
+
+// LogFields is the structured fields of a log.
+type LogFields map[string]interface{}
+
+// Logger is the logger used by the webhook validation.
+type Logger interface {
+	Debug(msg string, fields LogFields)
+	Info(msg string, fields LogFields)
+	Error(msg string, fields LogFields)
+}
+
+var defaultLogger Logger = logrusLogger{}
+
+// SetLogger sets the logger used by the package level functions and the
+// Validators created afterwards. It is backed by logrus if not set.
+func SetLogger(l Logger) {
+	if l != nil {
+		defaultLogger = l
+	}
+}
+
+// logrusLogger is the Logger backed by the standard logger of logrus.
+type logrusLogger struct{}
+
+func (logrusLogger) Debug(msg string, fields LogFields) {
+	logrus.WithFields(logrus.Fields(fields)).Debug(msg)
+}
+
+func (logrusLogger) Info(msg string, fields LogFields) {
+	logrus.WithFields(logrus.Fields(fields)).Info(msg)
+}
+
+func (logrusLogger) Error(msg string, fields LogFields) {
+	logrus.WithFields(logrus.Fields(fields)).Error(msg)
+}
@@ -0,0 +1,56 @@
+package client
+
+import "errors"
+
+// The reasons why the validation of webhook fails.
+const (
+	FailureReasonTooLarge       = "too_large"
+	FailureReasonInvalidPayload = "invalid_payload"
+	FailureReasonBadFormat      = "bad_format"
+	FailureReasonNoToken        = "no_token"
+	FailureReasonInvalidSecret  = "invalid_secret"
+	FailureReasonMismatch       = "mismatch"
+	FailureReasonOther          = "other"
+)
+
+// MetricsCollector collects the outcomes of webhook validation.
+type MetricsCollector interface {
+	// Received is called when a payload is received.
+	Received(payloadSize int)
+	// Passed is called when the payload passes the validation.
+	Passed()
+	// Failed is called when the payload fails the validation with the reason.
+	Failed(reason string)
+}
+
+type nopMetricsCollector struct{}
+
+func (nopMetricsCollector) Received(int)  {}
+func (nopMetricsCollector) Passed()       {}
+func (nopMetricsCollector) Failed(string) {}
+
+// failureReason returns the reason of the validation error.
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrPayloadTooLarge):
+		return FailureReasonTooLarge
+
+	case errors.Is(err, ErrInvalidPayload):
+		return FailureReasonInvalidPayload
+
+	case errors.Is(err, ErrBadSignatureFormat):
+		return FailureReasonBadFormat
+
+	case errors.Is(err, ErrNoHmacConfigured):
+		return FailureReasonNoToken
+
+	case errors.Is(err, ErrInvalidSecretFile):
+		return FailureReasonInvalidSecret
+
+	case errors.Is(err, ErrSignatureMismatch):
+		return FailureReasonMismatch
+
+	default:
+		return FailureReasonOther
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// SetMilestone sets the milestone of issue or pull request.
+func (cl client) SetMilestone(org, repo string, number int, milestoneNumber int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	_, _, err := cl.c.Issues.Edit(ctx, org, repo, number, &sdk.IssueRequest{Milestone: &milestoneNumber})
+	if err != nil {
+		return fmt.Errorf("failed to set milestone %d of %s: %w", milestoneNumber, PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// ClearMilestone removes the milestone of issue or pull request. It has to send
+// an explicit null which can't be expressed by sdk.IssueRequest.
+func (cl client) ClearMilestone(org, repo string, number int) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	u := fmt.Sprintf("repos/%s/%s/issues/%d", org, repo, number)
+	req, err := cl.c.NewRequest("PATCH", u, &struct {
+		Milestone interface{} `json:"milestone"`
+	}{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := cl.c.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("failed to clear milestone of %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// ListMilestones returns all the open milestones of the repo.
+func (cl client) ListMilestones(org, repo string) ([]*sdk.Milestone, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var milestones []*sdk.Milestone
+
+	opt := &sdk.MilestoneListOptions{ListOptions: sdk.ListOptions{Page: 1, PerPage: 100}}
+	for {
+		v, resp, err := cl.c.Issues.ListMilestones(ctx, org, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones of %s/%s: %w", org, repo, err)
+		}
+
+		milestones = append(milestones, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return milestones, nil
+}
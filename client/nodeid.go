@@ -0,0 +1,258 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// maxNodesPerQuery is the max number of node ids GitHub accepts in a nodes query.
+const maxNodesPerQuery = 100
+
+const (
+	// nodeIDCacheSize is the max number of node ids cached by a GraphQLClient.
+	nodeIDCacheSize = 1024
+
+	// nodeIDCacheTTL is how long a node id is cached. The node id never changes,
+	// but the login of user and the name of repo which it is looked up by may
+	// be changed by renaming or transferring.
+	nodeIDCacheTTL = time.Hour
+)
+
+// cachedNodeID is a node id in nodeIDCache.
+type cachedNodeID struct {
+	key      string
+	id       string
+	expireAt time.Time
+}
+
+// nodeIDCache keeps the node ids for a while, and evicts the least recently used
+// one if there are more than size. It is safe for concurrent use.
+type nodeIDCache struct {
+	lock sync.Mutex
+
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+func newNodeIDCache() *nodeIDCache {
+	return &nodeIDCache{
+		size:  nodeIDCacheSize,
+		ttl:   nodeIDCacheTTL,
+		items: map[string]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+func (c *nodeIDCache) get(key string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	v := e.Value.(*cachedNodeID)
+	if time.Now().After(v.expireAt) {
+		c.lru.Remove(e)
+		delete(c.items, key)
+
+		return "", false
+	}
+
+	c.lru.MoveToFront(e)
+
+	return v.id, true
+}
+
+func (c *nodeIDCache) set(key, id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	expireAt := time.Now().Add(c.ttl)
+
+	if e, ok := c.items[key]; ok {
+		v := e.Value.(*cachedNodeID)
+		v.id, v.expireAt = id, expireAt
+		c.lru.MoveToFront(e)
+
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(&cachedNodeID{key: key, id: id, expireAt: expireAt})
+
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.items, e.Value.(*cachedNodeID).key)
+	}
+}
+
+func (c *nodeIDCache) remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.lru.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// issueNodeIDKey returns the key of node id of issue or pull request in nodeIDCache.
+func issueNodeIDKey(org, repo string, number int) string {
+	return strings.ToLower(fmt.Sprintf("issue:%s/%s#%d", org, repo, number))
+}
+
+// nodeID returns the node id of key from the cache, or looks it up by query
+// and caches it. All the lookups of node id go through it.
+func (cl *GraphQLClient) nodeID(key string, query func(ctx context.Context) (string, error)) (string, error) {
+	if v, ok := cl.ids.get(key); ok {
+		return v, nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	id, err := query(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cl.ids.set(key, id)
+
+	return id, nil
+}
+
+// UserNodeID returns the node id of user, which is needed by the GraphQL mutations.
+// The result is cached for an hour.
+func (cl *GraphQLClient) UserNodeID(login string) (string, error) {
+	return cl.nodeID("user:"+strings.ToLower(login), func(ctx context.Context) (string, error) {
+		var q struct {
+			User struct {
+				ID string
+			} `graphql:"user(login: $login)"`
+		}
+
+		if err := cl.Query(ctx, &q, map[string]interface{}{"login": githubv4.String(login)}); err != nil {
+			return "", fmt.Errorf("failed to get the node id of user %s: %w", login, err)
+		}
+
+		return q.User.ID, nil
+	})
+}
+
+// UserLogins returns the logins of the users by their node ids. The node ids are
+// resolved in batches of 100, and the one which is not a user is not in the result.
+// The user may be renamed, so the result is not cached.
+func (cl *GraphQLClient) UserLogins(nodeIDs []string) (map[string]string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	r := make(map[string]string, len(nodeIDs))
+
+	for len(nodeIDs) > 0 {
+		batch := nodeIDs
+		if len(batch) > maxNodesPerQuery {
+			batch = batch[:maxNodesPerQuery]
+		}
+		nodeIDs = nodeIDs[len(batch):]
+
+		ids := make([]githubv4.ID, len(batch))
+		for i := range batch {
+			ids[i] = githubv4.ID(batch[i])
+		}
+
+		var q struct {
+			Nodes []struct {
+				User struct {
+					ID    string
+					Login string
+				} `graphql:"... on User"`
+			} `graphql:"nodes(ids: $ids)"`
+		}
+
+		if err := cl.Query(ctx, &q, map[string]interface{}{"ids": ids}); err != nil {
+			return nil, fmt.Errorf("failed to get the users of node ids: %w", err)
+		}
+
+		for _, n := range q.Nodes {
+			if n.User.ID != "" {
+				r[n.User.ID] = n.User.Login
+				cl.ids.set("user:"+strings.ToLower(n.User.Login), n.User.ID)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// RepoNodeID returns the node id of repo. The result is cached for an hour.
+func (cl *GraphQLClient) RepoNodeID(org, repo string) (string, error) {
+	return cl.nodeID(strings.ToLower("repo:"+org+"/"+repo), func(ctx context.Context) (string, error) {
+		var q struct {
+			Repository struct {
+				ID string
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+
+		vars := map[string]interface{}{
+			"owner": githubv4.String(org),
+			"name":  githubv4.String(repo),
+		}
+
+		if err := cl.Query(ctx, &q, vars); err != nil {
+			return "", fmt.Errorf("failed to get the node id of repo %s/%s: %w", org, repo, err)
+		}
+
+		return q.Repository.ID, nil
+	})
+}
+
+// IssueNodeID returns the node id of issue or pull request. The result is cached
+// for an hour.
+func (cl *GraphQLClient) IssueNodeID(org, repo string, number int) (string, error) {
+	return cl.nodeID(issueNodeIDKey(org, repo, number), func(ctx context.Context) (string, error) {
+		var q struct {
+			Repository struct {
+				IssueOrPullRequest struct {
+					Issue struct {
+						ID string
+					} `graphql:"... on Issue"`
+					PullRequest struct {
+						ID string
+					} `graphql:"... on PullRequest"`
+				} `graphql:"issueOrPullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+
+		vars := map[string]interface{}{
+			"owner":  githubv4.String(org),
+			"name":   githubv4.String(repo),
+			"number": githubv4.Int(number),
+		}
+
+		if err := cl.Query(ctx, &q, vars); err != nil {
+			return "", fmt.Errorf("failed to get the node id of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		v := &q.Repository.IssueOrPullRequest
+		id := v.Issue.ID
+		if id == "" {
+			id = v.PullRequest.ID
+		}
+
+		if id == "" {
+			return "", fmt.Errorf("failed to get the node id of %s: not found", PRInfo{org, repo, number})
+		}
+
+		return id, nil
+	})
+}
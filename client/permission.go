@@ -0,0 +1,111 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// permissionCacheTTL is how long the permission of user is cached.
+const permissionCacheTTL = time.Minute
+
+type cachedValue struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+// ttlCache caches the values for a while. It is safe for concurrent use.
+type ttlCache struct {
+	lock sync.Mutex
+
+	ttl   time.Duration
+	items map[string]cachedValue
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:   ttl,
+		items: map[string]cachedValue{},
+	}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	v, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(v.expireAt) {
+		delete(c.items, key)
+
+		return nil, false
+	}
+
+	return v.value, true
+}
+
+func (c *ttlCache) set(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+
+	// Drop the expired items lazily, so that the cache doesn't grow unbounded.
+	for k, v := range c.items {
+		if now.After(v.expireAt) {
+			delete(c.items, k)
+		}
+	}
+
+	c.items[key] = cachedValue{value: value, expireAt: now.Add(c.ttl)}
+}
+
+func permissionCacheKey(kind, org, repo, user string) string {
+	return strings.ToLower(kind + ":" + org + "/" + repo + ":" + user)
+}
+
+// GetUserPermission returns the permission of user on the repo, which is one of
+// admin, write, read and none. The result is cached for a minute.
+func (cl client) GetUserPermission(org, repo, user string) (string, error) {
+	key := permissionCacheKey("permission", org, repo, user)
+	if v, ok := cl.cache.get(key); ok {
+		return v.(string), nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	p, _, err := cl.c.Repositories.GetPermissionLevel(ctx, org, repo, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get permission of %s on %s/%s: %w", user, org, repo, err)
+	}
+
+	cl.cache.set(key, p.GetPermission())
+
+	return p.GetPermission(), nil
+}
+
+// IsRepoCollaborator tells whether the user is a collaborator of the repo.
+// The result is cached for a minute.
+func (cl client) IsRepoCollaborator(org, repo, user string) (bool, error) {
+	key := permissionCacheKey("collaborator", org, repo, user)
+	if v, ok := cl.cache.get(key); ok {
+		return v.(bool), nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	b, _, err := cl.c.Repositories.IsCollaborator(ctx, org, repo, user)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s is collaborator of %s/%s: %w", user, org, repo, err)
+	}
+
+	cl.cache.set(key, b)
+
+	return b, nil
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// ErrProjectsDisabled is returned when the classic projects are disabled for
+// the org or repo.
+var ErrProjectsDisabled = errors.New("classic projects are disabled")
+
+const (
+	// CardPositionTop moves the card to the top of column.
+	CardPositionTop = "top"
+	// CardPositionBottom moves the card to the bottom of column.
+	CardPositionBottom = "bottom"
+)
+
+// CardPositionAfter returns the position which moves the card after the card
+// of cardID in the same project.
+func CardPositionAfter(cardID int64) string {
+	return "after:" + strconv.FormatInt(cardID, 10)
+}
+
+// The helpers of projects below are for the classic projects by the REST API.
+// The projects (v2) are only supported by the GraphQL API, see GraphQLClient.
+
+// ListProjectColumns returns all the columns of classic project.
+func (cl client) ListProjectColumns(projectID int64) ([]*sdk.ProjectColumn, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var columns []*sdk.ProjectColumn
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Projects.ListProjectColumns(ctx, projectID, opt)
+		if err != nil {
+			return nil, projectError(fmt.Sprintf("list columns of project %d", projectID), resp, err)
+		}
+
+		columns = append(columns, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return columns, nil
+}
+
+// CreateProjectCard creates a card in the column of classic project. The card is
+// either a note or an issue/pull request specified by its id rather than number.
+func (cl client) CreateProjectCard(columnID int64, opt *sdk.ProjectCardOptions) (*sdk.ProjectCard, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, resp, err := cl.c.Projects.CreateProjectCard(ctx, columnID, opt)
+	if err != nil {
+		return nil, projectError(fmt.Sprintf("create card in column %d", columnID), resp, err)
+	}
+
+	return v, nil
+}
+
+// MoveProjectCard moves the card to the position of the column. The position is
+// one of CardPositionTop, CardPositionBottom and CardPositionAfter. The columnID
+// can be zero if the card is moved in the same column.
+func (cl client) MoveProjectCard(cardID, columnID int64, position string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	resp, err := cl.c.Projects.MoveProjectCard(ctx, cardID, &sdk.ProjectCardMoveOptions{
+		Position: position,
+		ColumnID: columnID,
+	})
+	if err != nil {
+		return projectError(fmt.Sprintf("move card %d to %s of column %d", cardID, position, columnID), resp, err)
+	}
+
+	return nil
+}
+
+// projectError returns the error of operation on the classic projects. Github
+// responds 410 Gone if the classic projects are disabled.
+func projectError(op string, resp *sdk.Response, err error) error {
+	if resp != nil && resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("failed to %s: %w: %v", op, ErrProjectsDisabled, err)
+	}
+
+	return fmt.Errorf("failed to %s: %w", op, err)
+}
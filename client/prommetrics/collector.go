@@ -0,0 +1,66 @@
+// Package prommetrics implements client.MetricsCollector with Prometheus.
+package prommetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/opensourceways/robot-github-lib/client"
+)
+
+// Collector collects the outcomes of webhook validation as Prometheus metrics.
+type Collector struct {
+	received    prometheus.Counter
+	passed      prometheus.Counter
+	failed      *prometheus.CounterVec
+	payloadSize prometheus.Histogram
+}
+
+// NewCollector returns a Collector whose metrics are registered to reg.
+func NewCollector(reg prometheus.Registerer, namespace string) (*Collector, error) {
+	c := &Collector{
+		received: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_received_total",
+			Help:      "Total number of the received webhooks.",
+		}),
+		passed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_validation_passed_total",
+			Help:      "Total number of the webhooks which pass the validation.",
+		}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_validation_failed_total",
+			Help:      "Total number of the webhooks which fail the validation by reason.",
+		}, []string{"reason"}),
+		payloadSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "webhook_payload_size_bytes",
+			Help:      "Size of the payload of webhooks.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}),
+	}
+
+	for _, m := range []prometheus.Collector{c.received, c.passed, c.failed, c.payloadSize} {
+		if err := reg.Register(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+var _ client.MetricsCollector = (*Collector)(nil)
+
+func (c *Collector) Received(payloadSize int) {
+	c.received.Inc()
+	c.payloadSize.Observe(float64(payloadSize))
+}
+
+func (c *Collector) Passed() {
+	c.passed.Inc()
+}
+
+func (c *Collector) Failed(reason string) {
+	c.failed.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+func (cl client) GetBranchProtection(org, repo, branch string) (*sdk.Protection, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	p, _, err := cl.c.Repositories.GetBranchProtection(ctx, org, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protection of %s/%s:%s: %w", org, repo, branch, err)
+	}
+
+	return p, nil
+}
+
+func (cl client) UpdateBranchProtection(org, repo, branch string, req *sdk.ProtectionRequest) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Repositories.UpdateBranchProtection(ctx, org, repo, branch, req); err != nil {
+		return fmt.Errorf("failed to update protection of %s/%s:%s: %w", org, repo, branch, err)
+	}
+
+	return nil
+}
+
+// PatchBranchProtection reads the current protection of branch, changes it by
+// mutate and writes it back, because GitHub requires the entire protection to
+// update. The mutate gets an empty request if the branch is not protected.
+func (cl client) PatchBranchProtection(org, repo, branch string, mutate func(*sdk.ProtectionRequest)) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	req := &sdk.ProtectionRequest{}
+
+	p, r, err := cl.c.Repositories.GetBranchProtection(ctx, org, repo, branch)
+	if err != nil {
+		if r == nil || r.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("failed to get protection of %s/%s:%s: %w", org, repo, branch, err)
+		}
+	} else {
+		req = toProtectionRequest(p)
+	}
+
+	mutate(req)
+
+	if _, _, err := cl.c.Repositories.UpdateBranchProtection(ctx, org, repo, branch, req); err != nil {
+		return fmt.Errorf("failed to update protection of %s/%s:%s: %w", org, repo, branch, err)
+	}
+
+	return nil
+}
+
+// toProtectionRequest converts the protection to the request which keeps it unchanged.
+func toProtectionRequest(p *sdk.Protection) *sdk.ProtectionRequest {
+	req := &sdk.ProtectionRequest{
+		RequiredStatusChecks: p.RequiredStatusChecks,
+	}
+
+	if v := p.RequiredPullRequestReviews; v != nil {
+		req.RequiredPullRequestReviews = &sdk.PullRequestReviewsEnforcementRequest{
+			DismissStaleReviews:          v.DismissStaleReviews,
+			RequireCodeOwnerReviews:      v.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: v.RequiredApprovingReviewCount,
+		}
+
+		if d := v.DismissalRestrictions; d != nil {
+			users, teams := userLogins(d.Users), teamSlugs(d.Teams)
+			req.RequiredPullRequestReviews.DismissalRestrictionsRequest = &sdk.DismissalRestrictionsRequest{
+				Users: &users,
+				Teams: &teams,
+			}
+		}
+	}
+
+	if p.EnforceAdmins != nil {
+		req.EnforceAdmins = p.EnforceAdmins.Enabled
+	}
+
+	if v := p.Restrictions; v != nil {
+		apps := make([]string, len(v.Apps))
+		for i := range v.Apps {
+			apps[i] = v.Apps[i].GetSlug()
+		}
+
+		req.Restrictions = &sdk.BranchRestrictionsRequest{
+			Users: userLogins(v.Users),
+			Teams: teamSlugs(v.Teams),
+			Apps:  apps,
+		}
+	}
+
+	if p.RequireLinearHistory != nil {
+		req.RequireLinearHistory = sdk.Bool(p.RequireLinearHistory.Enabled)
+	}
+
+	if p.AllowForcePushes != nil {
+		req.AllowForcePushes = sdk.Bool(p.AllowForcePushes.Enabled)
+	}
+
+	if p.AllowDeletions != nil {
+		req.AllowDeletions = sdk.Bool(p.AllowDeletions.Enabled)
+	}
+
+	return req
+}
+
+func userLogins(users []*sdk.User) []string {
+	r := make([]string, len(users))
+	for i := range users {
+		r[i] = users[i].GetLogin()
+	}
+
+	return r
+}
+
+func teamSlugs(teams []*sdk.Team) []string {
+	r := make([]string, len(teams))
+	for i := range teams {
+		r[i] = teams[i].GetSlug()
+	}
+
+	return r
+}
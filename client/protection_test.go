@@ -0,0 +1,133 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+const testProtection = `{
+	"required_status_checks": {"strict": true, "contexts": ["ci"]},
+	"required_pull_request_reviews": {
+		"dismiss_stale_reviews": true,
+		"required_approving_review_count": 1,
+		"dismissal_restrictions": {"users": [{"login": "alice"}], "teams": [{"slug": "core"}]}
+	},
+	"enforce_admins": {"enabled": true},
+	"restrictions": {"users": [{"login": "bob"}], "teams": [], "apps": [{"slug": "robot"}]},
+	"required_linear_history": {"enabled": true},
+	"allow_force_pushes": {"enabled": false},
+	"allow_deletions": {"enabled": false}
+}`
+
+func TestPatchBranchProtection(t *testing.T) {
+	cases := []struct {
+		name         string
+		getStatus    int
+		getBody      string
+		wantRequests []string
+		wantBody     string
+		wantErr      bool
+	}{
+		{
+			name:      "read, modify and write",
+			getStatus: http.StatusOK,
+			getBody:   testProtection,
+			wantRequests: []string{
+				"GET /repos/owner/repo/branches/main/protection",
+				"PUT /repos/owner/repo/branches/main/protection",
+			},
+			wantBody: `{
+				"required_status_checks": {"strict": true, "contexts": ["ci"]},
+				"required_pull_request_reviews": {
+					"dismissal_restrictions": {"users": ["alice"], "teams": ["core"]},
+					"dismiss_stale_reviews": true,
+					"require_code_owner_reviews": false,
+					"required_approving_review_count": 2
+				},
+				"enforce_admins": true,
+				"restrictions": {"users": ["bob"], "teams": [], "apps": ["robot"]},
+				"required_linear_history": true,
+				"allow_force_pushes": false,
+				"allow_deletions": false
+			}`,
+		},
+		{
+			name:      "not protected",
+			getStatus: http.StatusNotFound,
+			getBody:   `{"message":"Branch not protected"}`,
+			wantRequests: []string{
+				"GET /repos/owner/repo/branches/main/protection",
+				"PUT /repos/owner/repo/branches/main/protection",
+			},
+			wantBody: `{
+				"required_status_checks": null,
+				"required_pull_request_reviews": {
+					"dismiss_stale_reviews": false,
+					"require_code_owner_reviews": false,
+					"required_approving_review_count": 2
+				},
+				"enforce_admins": false,
+				"restrictions": null
+			}`,
+		},
+		{
+			name:         "failed to read",
+			getStatus:    http.StatusForbidden,
+			getBody:      `{"message":"Resource not accessible by integration"}`,
+			wantRequests: []string{"GET /repos/owner/repo/branches/main/protection"},
+			wantErr:      true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.WriteHeader(c.getStatus)
+					fmt.Fprint(w, c.getBody)
+
+					return
+				}
+
+				fmt.Fprint(w, testProtection)
+			})
+
+			err := s.client(t).PatchBranchProtection("owner", "repo", "main", func(req *sdk.ProtectionRequest) {
+				if req.RequiredPullRequestReviews == nil {
+					req.RequiredPullRequestReviews = &sdk.PullRequestReviewsEnforcementRequest{}
+				}
+
+				req.RequiredPullRequestReviews.RequiredApprovingReviewCount = 2
+			})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+
+			if got := s.received(); fmt.Sprint(got) != fmt.Sprint(c.wantRequests) {
+				t.Fatalf("got requests %v, want %v", got, c.wantRequests)
+			}
+
+			if c.wantBody == "" {
+				return
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal([]byte(s.bodies[1]), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(c.wantBody), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got body %s, want %s", s.bodies[1], c.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// The methods of merging pull request.
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+var (
+	// ErrNotMergeable is returned when the pull request can't be merged.
+	ErrNotMergeable = errors.New("pull request is not mergeable")
+
+	// ErrHeadSHAMismatch is returned when the head of pull request is not the expected sha.
+	ErrHeadSHAMismatch = errors.New("head sha of pull request mismatch")
+
+	// ErrBranchUpToDate is returned when updating the branch of pull request
+	// which has all the commits of the base branch.
+	ErrBranchUpToDate = errors.New("branch of pull request is up to date")
+)
+
+// CreatePullRequestOptions is the optional settings of creating pull request.
+type CreatePullRequestOptions struct {
+	MaintainerCanModify bool
+	Draft               bool
+}
+
+// UpdatePullRequestOptions is the fields of pull request to update.
+// The nil field will not be updated.
+type UpdatePullRequestOptions struct {
+	Title *string
+	Body  *string
+	Base  *string
+}
+
+func (cl client) CreatePullRequest(
+	org, repo, title, body, head, base string, opt *CreatePullRequestOptions,
+) (*sdk.PullRequest, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	req := &sdk.NewPullRequest{
+		Title: sdk.String(title),
+		Body:  sdk.String(body),
+		Head:  sdk.String(head),
+		Base:  sdk.String(base),
+	}
+	if opt != nil {
+		req.MaintainerCanModify = sdk.Bool(opt.MaintainerCanModify)
+		req.Draft = sdk.Bool(opt.Draft)
+	}
+
+	pr, _, err := cl.c.PullRequests.Create(ctx, org, repo, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request on %s/%s from %s to %s: %w", org, repo, head, base, err)
+	}
+
+	return pr, nil
+}
+
+func (cl client) UpdatePullRequest(
+	org, repo string, number int, opt UpdatePullRequestOptions,
+) (*sdk.PullRequest, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	req := &sdk.PullRequest{
+		Title: opt.Title,
+		Body:  opt.Body,
+	}
+	if opt.Base != nil {
+		req.Base = &sdk.PullRequestBranch{Ref: opt.Base}
+	}
+
+	pr, _, err := cl.c.PullRequests.Edit(ctx, org, repo, number, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return pr, nil
+}
+
+// UpdatePullRequestBranch merges the latest changes of base branch into the
+// head branch of pull request, which is the same as the "Update branch" button.
+// If expectedHeadSHA is not empty, it fails with ErrHeadSHAMismatch when the head
+// is not that sha, which avoids racing with the new pushes. It fails with
+// ErrBranchUpToDate if there is nothing to merge. The update is queued and done
+// by GitHub in background.
+func (cl client) UpdatePullRequestBranch(org, repo string, number int, expectedHeadSHA string) error {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var opt *sdk.PullRequestBranchUpdateOptions
+	if expectedHeadSHA != "" {
+		opt = &sdk.PullRequestBranchUpdateOptions{ExpectedHeadSHA: sdk.String(expectedHeadSHA)}
+	}
+
+	_, r, err := cl.c.PullRequests.UpdateBranch(ctx, org, repo, number, opt)
+	if err != nil {
+		pr := PRInfo{org, repo, number}
+
+		var accepted *sdk.AcceptedError
+		if errors.As(err, &accepted) {
+			return nil
+		}
+
+		if e, ok := AsGitHubError(err); ok && r != nil && r.StatusCode == http.StatusUnprocessableEntity {
+			switch msg := strings.ToLower(e.Message); {
+			case strings.Contains(msg, "expected head sha"):
+				return fmt.Errorf("failed to update branch of pull request %s: %w: %v", pr, ErrHeadSHAMismatch, err)
+
+			case strings.Contains(msg, "no new commits") || strings.Contains(msg, "up to date"):
+				return fmt.Errorf("failed to update branch of pull request %s: %w: %v", pr, ErrBranchUpToDate, err)
+			}
+		}
+
+		return fmt.Errorf("failed to update branch of pull request %s: %w", pr, err)
+	}
+
+	return nil
+}
+
+// Merge merges the pull request with the method if its head is sha.
+func (cl client) Merge(org, repo string, number int, method, sha string) error {
+	return cl.MergeWithMessage(org, repo, number, method, sha, "", "")
+}
+
+// MergeWithMessage is the same as Merge except that the title and message of
+// merge commit can be specified. GitHub generates them if they are empty.
+func (cl client) MergeWithMessage(org, repo string, number int, method, sha, title, message string) error {
+	switch method {
+	case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+	default:
+		return fmt.Errorf("unknown merge method: %s", method)
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	opt := &sdk.PullRequestOptions{
+		CommitTitle: title,
+		SHA:         sha,
+		MergeMethod: method,
+	}
+
+	pr := PRInfo{org, repo, number}
+
+	result, r, err := cl.c.PullRequests.Merge(ctx, org, repo, number, message, opt)
+	if err != nil {
+		if r != nil {
+			switch r.StatusCode {
+			case http.StatusMethodNotAllowed:
+				return fmt.Errorf("failed to merge %s: %w: %v", pr, ErrNotMergeable, err)
+
+			case http.StatusConflict:
+				return fmt.Errorf("failed to merge %s: %w: %v", pr, ErrHeadSHAMismatch, err)
+			}
+		}
+
+		return fmt.Errorf("failed to merge %s: %w", pr, err)
+	}
+
+	// The merged is missing only in the synthesized response of dry run.
+	if result.Merged != nil && !*result.Merged {
+		return fmt.Errorf("failed to merge %s: %w: %s", pr, ErrNotMergeable, result.GetMessage())
+	}
+
+	return nil
+}
+
+// RenderMergeMessage renders the text/template with the pull request, for example
+// "{{.GetTitle}} (#{{.GetNumber}})". It can be used to generate the title and
+// message of merge commit.
+func RenderMergeMessage(tmpl string, pr *sdk.PullRequest) (string, error) {
+	t, err := template.New("merge").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	b := new(bytes.Buffer)
+	if err := t.Execute(b, pr); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// PRListOptions is the filters of listing pull requests.
+type PRListOptions struct {
+	// State is one of "open", "closed" and "all". It is "open" if empty.
+	State string
+	// Base is the base branch.
+	Base string
+	// Author is the login of author.
+	Author string
+	// Labels are the labels which the pull requests must all have.
+	Labels []string
+}
+
+// ListPullRequests returns all the pull requests matching the filters. If there
+// are labels in the filters, the pull requests are found by the search API whose
+// rate limit is much lower than the other APIs, 30 requests per minute, and each
+// of them is got then. Otherwise, they are listed and filtered by the author.
+func (cl client) ListPullRequests(org, repo string, opts PRListOptions) ([]*sdk.PullRequest, error) {
+	if opts.State == "" {
+		opts.State = "open"
+	}
+
+	if len(opts.Labels) > 0 {
+		return cl.searchPullRequests(org, repo, opts)
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var prs []*sdk.PullRequest
+
+	opt := &sdk.PullRequestListOptions{
+		State:       opts.State,
+		Base:        opts.Base,
+		ListOptions: sdk.ListOptions{Page: 1, PerPage: 100},
+	}
+	for {
+		v, resp, err := cl.c.PullRequests.List(ctx, org, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests of %s/%s: %w", org, repo, err)
+		}
+
+		for _, pr := range v {
+			if opts.Author == "" || strings.EqualFold(pr.GetUser().GetLogin(), opts.Author) {
+				prs = append(prs, pr)
+			}
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return prs, nil
+}
+
+func (cl client) searchPullRequests(org, repo string, opts PRListOptions) ([]*sdk.PullRequest, error) {
+	r, err := cl.SearchIssues(buildPRSearchQuery(org, repo, opts), SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The search API returns the issues, so get the pull requests one by one.
+	// Each of them has its own timeout, since there may be many of them.
+	prs := make([]*sdk.PullRequest, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		pr, err := cl.getPullRequest(org, repo, issue.GetNumber())
+		if err != nil {
+			return nil, err
+		}
+
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
+
+func (cl client) getPullRequest(org, repo string, number int) (*sdk.PullRequest, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	pr, _, err := cl.c.PullRequests.Get(ctx, org, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return pr, nil
+}
+
+// buildPRSearchQuery returns the query of search API for the filters.
+func buildPRSearchQuery(org, repo string, opts PRListOptions) string {
+	items := []string{"is:pr", "repo:" + org + "/" + repo}
+
+	switch opts.State {
+	case "open", "closed":
+		items = append(items, "is:"+opts.State)
+	}
+
+	if opts.Base != "" {
+		items = append(items, "base:"+searchQualifierValue(opts.Base))
+	}
+
+	if opts.Author != "" {
+		items = append(items, "author:"+searchQualifierValue(opts.Author))
+	}
+
+	for _, l := range opts.Labels {
+		items = append(items, "label:"+searchQualifierValue(l))
+	}
+
+	return strings.Join(items, " ")
+}
+
+// searchQualifierValue quotes the value of search qualifier if it has spaces.
+// The search syntax doesn't support escaping quotes, so they are removed.
+func searchQualifierValue(v string) string {
+	v = strings.ReplaceAll(v, `"`, "")
+	if strings.ContainsAny(v, " \t") {
+		return `"` + v + `"`
+	}
+
+	return v
+}
+
+// FindPullRequestsForCommit returns all the pull requests associated with the
+// commit. A commit can belong to several pull requests, both open and closed.
+func (cl client) FindPullRequestsForCommit(org, repo, sha string) ([]*sdk.PullRequest, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var prs []*sdk.PullRequest
+
+	opt := &sdk.PullRequestListOptions{ListOptions: sdk.ListOptions{Page: 1, PerPage: 100}}
+	for {
+		v, resp, err := cl.c.PullRequests.ListPullRequestsWithCommit(ctx, org, repo, sha, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests of commit %s in %s/%s: %w", sha, org, repo, err)
+		}
+
+		prs = append(prs, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return prs, nil
+}
+
+// GetReviewSummary returns the reviewers who approve the pull request and those
+// who request changes currently. Only the latest review of each reviewer counts,
+// except the commented ones which don't change the state of reviewer, and the
+// dismissed review clears it. The reviewers are sorted by login.
+func (cl client) GetReviewSummary(org, repo string, number int) (approvals []string, changesRequested []string, err error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	states := map[string]string{}
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.PullRequests.ListReviews(ctx, org, repo, number, opt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list reviews of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		// The reviews are in chronological order.
+		for _, r := range v {
+			switch state := r.GetState(); state {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				states[r.GetUser().GetLogin()] = state
+			}
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	for login, state := range states {
+		switch state {
+		case "APPROVED":
+			approvals = append(approvals, login)
+
+		case "CHANGES_REQUESTED":
+			changesRequested = append(changesRequested, login)
+		}
+	}
+
+	sort.Strings(approvals)
+	sort.Strings(changesRequested)
+
+	return approvals, changesRequested, nil
+}
+
+// ListPullRequestCommits returns all the commits of pull request, of which the
+// Commit.Verification tells whether the commit is signed by GPG or S/MIME.
+// Github returns at most 250 commits of a pull request.
+func (cl client) ListPullRequestCommits(org, repo string, number int) ([]*sdk.RepositoryCommit, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var commits []*sdk.RepositoryCommit
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.PullRequests.ListCommits(ctx, org, repo, number, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		commits = append(commits, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return commits, nil
+}
+
+// AllCommitsVerified tells whether all the commits have the verified signature,
+// and returns the shas of the commits which don't.
+func AllCommitsVerified(commits []*sdk.RepositoryCommit) (bool, []string) {
+	var unverified []string
+	for _, c := range commits {
+		if !c.GetCommit().GetVerification().GetVerified() {
+			unverified = append(unverified, c.GetSHA())
+		}
+	}
+
+	return len(unverified) == 0, unverified
+}
+
+const (
+	// mergeabilityMaxWait is how long to wait for github to compute the mergeability
+	// if the ctx has no deadline.
+	mergeabilityMaxWait = 30 * time.Second
+
+	mergeabilityBaseDelay = time.Second
+	mergeabilityMaxDelay  = 8 * time.Second
+)
+
+// MergeabilityState returns whether the pull request can be merged without
+// conflicts and the mergeable state which is one of "clean", "dirty", "blocked",
+// "behind", "unstable", "has_hooks", "draft" and "unknown". Github computes the
+// mergeability asynchronously, so it polls the pull request with backoff until
+// the mergeability is computed. The mergeable is nil if it is still unknown
+// before the deadline of ctx, or after 30 seconds if the ctx has no deadline.
+// The polling stops with the error of ctx once it is canceled.
+func (cl client) MergeabilityState(ctx context.Context, org, repo string, number int) (mergeable *bool, mergeState string, err error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(mergeabilityMaxWait)
+	}
+
+	delay := mergeabilityBaseDelay
+
+	for {
+		c, cancel := cl.newContextFrom(ctx)
+		pr, _, err := cl.c.PullRequests.Get(c, org, repo, number)
+		cancel()
+
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get pull request %s: %w", PRInfo{org, repo, number}, err)
+		}
+
+		// The closed pull request is never computed.
+		if pr.Mergeable != nil || pr.GetState() == "closed" || !time.Now().Add(delay).Before(deadline) {
+			return pr.Mergeable, pr.GetMergeableState(), nil
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			return nil, "", fmt.Errorf("failed to get the mergeability of %s: %w", PRInfo{org, repo, number}, ctx.Err())
+
+		case <-t.C:
+		}
+
+		if delay *= 2; delay > mergeabilityMaxDelay {
+			delay = mergeabilityMaxDelay
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMergeabilityStateHonorsDeadlineOfCtx(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"state":"open","mergeable":null,"mergeable_state":"unknown"}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	mergeable, state, err := s.client(t).MergeabilityState(ctx, "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mergeable != nil || state != "unknown" {
+		t.Errorf("got mergeable %v and state %s, want nil and unknown", mergeable, state)
+	}
+
+	// The next poll would be after the deadline, so it gives up at once.
+	if got := s.received(); len(got) != 1 {
+		t.Errorf("got requests %v, want only one", got)
+	}
+}
+
+func TestGetReviewSummary(t *testing.T) {
+	cases := []struct {
+		name                 string
+		pages                []string
+		wantApprovals        []string
+		wantChangesRequested []string
+	}{
+		{
+			name: "latest review of each user wins across pages",
+			pages: []string{
+				`[
+					{"user":{"login":"alice"},"state":"APPROVED"},
+					{"user":{"login":"bob"},"state":"APPROVED"}
+				]`,
+				`[
+					{"user":{"login":"alice"},"state":"CHANGES_REQUESTED"},
+					{"user":{"login":"alice"},"state":"COMMENTED"},
+					{"user":{"login":"carol"},"state":"CHANGES_REQUESTED"},
+					{"user":{"login":"bob"},"state":"DISMISSED"}
+				]`,
+			},
+			wantChangesRequested: []string{"alice", "carol"},
+		},
+		{
+			name: "approval after changes requested",
+			pages: []string{
+				`[
+					{"user":{"login":"eve"},"state":"CHANGES_REQUESTED"},
+					{"user":{"login":"dave"},"state":"COMMENTED"}
+				]`,
+				`[{"user":{"login":"eve"},"state":"APPROVED"}]`,
+			},
+			wantApprovals: []string{"eve"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var s *testServer
+			s = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				s.writePage(w, r, c.pages)
+			})
+
+			approvals, changesRequested, err := s.client(t).GetReviewSummary("owner", "repo", 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(approvals) != fmt.Sprint(c.wantApprovals) {
+				t.Errorf("got approvals %v, want %v", approvals, c.wantApprovals)
+			}
+
+			if fmt.Sprint(changesRequested) != fmt.Sprint(c.wantChangesRequested) {
+				t.Errorf("got changes requested %v, want %v", changesRequested, c.wantChangesRequested)
+			}
+
+			if got := s.received(); len(got) != len(c.pages) {
+				t.Errorf("got requests %v, want one per page", got)
+			}
+		})
+	}
+}
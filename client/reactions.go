@@ -0,0 +1,69 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ErrInvalidReaction is returned when the content of reaction is not allowed by GitHub.
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+var validReactions = sets.NewString("+1", "-1", "laugh", "confused", "heart", "hooray", "rocket", "eyes")
+
+func checkReaction(content string) error {
+	if !validReactions.Has(content) {
+		return fmt.Errorf("%w: %s, it must be one of %v", ErrInvalidReaction, content, validReactions.List())
+	}
+
+	return nil
+}
+
+// CreateCommentReaction creates the reaction on the comment of issue or PR.
+func (cl client) CreateCommentReaction(org, repo string, commentID int64, content string) error {
+	if err := checkReaction(content); err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Reactions.CreateIssueCommentReaction(ctx, org, repo, commentID, content); err != nil {
+		return fmt.Errorf("failed to create reaction on comment %d of %s/%s: %w", commentID, org, repo, err)
+	}
+
+	return nil
+}
+
+// CreateIssueReaction creates the reaction on the issue or PR.
+func (cl client) CreateIssueReaction(org, repo string, number int, content string) error {
+	if err := checkReaction(content); err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Reactions.CreateIssueReaction(ctx, org, repo, number, content); err != nil {
+		return fmt.Errorf("failed to create reaction on %s: %w", PRInfo{org, repo, number}, err)
+	}
+
+	return nil
+}
+
+// CreateReviewCommentReaction creates the reaction on the review comment of PR.
+func (cl client) CreateReviewCommentReaction(org, repo string, commentID int64, content string) error {
+	if err := checkReaction(content); err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Reactions.CreatePullRequestCommentReaction(ctx, org, repo, commentID, content); err != nil {
+		return fmt.Errorf("failed to create reaction on review comment %d of %s/%s: %w", commentID, org, repo, err)
+	}
+
+	return nil
+}
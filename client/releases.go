@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"path/filepath"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+// ErrAssetExists is returned when the release has an asset of the same name.
+// The asset should be deleted first if it is to be replaced.
+var ErrAssetExists = errors.New("release asset already exists")
+
+// CreateRelease creates the release. The tag is created from the target
+// commitish if it doesn't exist.
+func (cl client) CreateRelease(org, repo string, rel *sdk.RepositoryRelease) (*sdk.RepositoryRelease, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	v, _, err := cl.c.Repositories.CreateRelease(ctx, org, repo, rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release %s of %s/%s: %w", rel.GetTagName(), org, repo, err)
+	}
+
+	return v, nil
+}
+
+// UploadReleaseAsset uploads the content of r as the asset of release. The content
+// type is determined by the extension of name and is application/octet-stream if
+// unknown. The asset is uploaded to the upload url, see WithBaseURL for the
+// GitHub Enterprise Server.
+func (cl client) UploadReleaseAsset(org, repo string, releaseID int64, name string, r io.Reader) error {
+	// The size of content must be known in advance.
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read the asset %s: %w", name, err)
+	}
+
+	mediaType := mime.TypeByExtension(filepath.Ext(name))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+
+	u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?name=%s", org, repo, releaseID, url.QueryEscape(name))
+	req, err := cl.c.NewUploadRequest(u, bytes.NewReader(content), int64(len(content)), mediaType)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, err := cl.c.Do(ctx, req, nil); err != nil {
+		if isAlreadyExists(err) {
+			return fmt.Errorf("failed to upload %s to release %d of %s/%s: %w", name, releaseID, org, repo, ErrAssetExists)
+		}
+
+		return fmt.Errorf("failed to upload %s to release %d of %s/%s: %w", name, releaseID, org, repo, err)
+	}
+
+	return nil
+}
+
+// isAlreadyExists tells whether the err is the validation failure because
+// the resource exists.
+func isAlreadyExists(err error) bool {
+	var e *sdk.ErrorResponse
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	for _, v := range e.Errors {
+		if v.Code == "already_exists" {
+			return true
+		}
+	}
+
+	return false
+}
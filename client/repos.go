@@ -0,0 +1,291 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	sdk "github.com/google/go-github/v36/github"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// RepoListOptions is the filters of listing the repos of org.
+type RepoListOptions struct {
+	// Type is one of "all", "public", "private", "forks", "sources" and "member".
+	// It is "all" if empty.
+	Type string
+	// Sort is one of "created", "updated", "pushed" and "full_name".
+	Sort string
+	// Direction is "asc" or "desc".
+	Direction string
+	// ExcludeArchived excludes the archived repos, which is done by the client
+	// because the API doesn't support it.
+	ExcludeArchived bool
+}
+
+// ListOrgRepos returns all the repos of org matching the filters.
+func (cl client) ListOrgRepos(org string, opts RepoListOptions) ([]*sdk.Repository, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var repos []*sdk.Repository
+
+	opt := &sdk.RepositoryListByOrgOptions{
+		Type:        opts.Type,
+		Sort:        opts.Sort,
+		Direction:   opts.Direction,
+		ListOptions: sdk.ListOptions{Page: 1, PerPage: 100},
+	}
+	for {
+		v, resp, err := cl.c.Repositories.ListByOrg(ctx, org, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos of %s: %w", org, err)
+		}
+
+		for _, r := range v {
+			if opts.ExcludeArchived && r.GetArchived() {
+				continue
+			}
+
+			repos = append(repos, r)
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return repos, nil
+}
+
+// RepoError is the error of operation on a repo.
+type RepoError struct {
+	Repo string
+	Err  error
+}
+
+func (e RepoError) Error() string {
+	return e.Repo + ": " + e.Err.Error()
+}
+
+func (e RepoError) Unwrap() error {
+	return e.Err
+}
+
+// RepoErrors is the errors of operation on the repos.
+type RepoErrors []RepoError
+
+func (e RepoErrors) Error() string {
+	s := make([]string, len(e))
+	for i := range e {
+		s[i] = e[i].Error()
+	}
+
+	return fmt.Sprintf("failed on %d repos: %s", len(e), strings.Join(s, "; "))
+}
+
+// ForEachRepo is the same as ForEachRepoCtx with the background context.
+func ForEachRepo(repos []*sdk.Repository, concurrency int, fn func(repo *sdk.Repository) error) error {
+	return ForEachRepoCtx(context.Background(), repos, concurrency, fn)
+}
+
+// ForEachRepoCtx calls fn for each repo by at most concurrency goroutines, and
+// at least one. The errors of fn are returned as RepoErrors in the order of repos.
+// The repos not started yet are skipped when the ctx is done, and the error of
+// ctx is returned if fn succeeds on all the started repos.
+func ForEachRepoCtx(
+	ctx context.Context, repos []*sdk.Repository, concurrency int, fn func(repo *sdk.Repository) error,
+) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(repos))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(repos); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				errs[i] = fn(repos[i])
+			}
+		}()
+	}
+
+	canceled := false
+
+loop:
+	for i := range repos {
+		select {
+		case <-ctx.Done():
+			canceled = true
+
+			break loop
+
+		case indexes <- i:
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	var r RepoErrors
+	for i, err := range errs {
+		if err != nil {
+			r = append(r, RepoError{Repo: repos[i].GetFullName(), Err: err})
+		}
+	}
+
+	if len(r) > 0 {
+		return r
+	}
+
+	if canceled {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// GetDefaultBranch returns the default branch of repo, which may be neither
+// main nor master. The result is cached for a minute.
+func (cl client) GetDefaultBranch(org, repo string) (string, error) {
+	key := strings.ToLower("default-branch:" + org + "/" + repo)
+	if v, ok := cl.cache.get(key); ok {
+		return v.(string), nil
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	r, _, err := cl.c.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repo %s/%s: %w", org, repo, err)
+	}
+
+	cl.cache.set(key, r.GetDefaultBranch())
+
+	return r.GetDefaultBranch(), nil
+}
+
+// GetLatestCommit returns the head commit of branch, of which the sha can be used
+// to create the statuses and check runs. It is the default branch if branch is empty.
+func (cl client) GetLatestCommit(org, repo, branch string) (*sdk.RepositoryCommit, error) {
+	if branch == "" {
+		b, err := cl.GetDefaultBranch(org, repo)
+		if err != nil {
+			return nil, err
+		}
+
+		branch = b
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	b, _, err := cl.c.Repositories.GetBranch(ctx, org, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %s of %s/%s: %w", branch, org, repo, err)
+	}
+
+	return b.GetCommit(), nil
+}
+
+const (
+	maxTopicLength = 50
+	maxTopics      = 20
+)
+
+// topicRegexp is the format of topic, which is the lowercase letters, numbers
+// and hyphens, and starts with a letter or number.
+var topicRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// GetRepoTopics returns the topics of repo.
+func (cl client) GetRepoTopics(org, repo string) ([]string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	topics, _, err := cl.c.Repositories.ListAllTopics(ctx, org, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics of %s/%s: %w", org, repo, err)
+	}
+
+	return topics, nil
+}
+
+// SetRepoTopics replaces all the topics of repo with the topics. The topics
+// are validated before the call, see validateTopics. Use AddRepoTopics to keep
+// the existing ones.
+func (cl client) SetRepoTopics(org, repo string, topics []string) error {
+	if err := validateTopics(topics); err != nil {
+		return err
+	}
+
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	if _, _, err := cl.c.Repositories.ReplaceAllTopics(ctx, org, repo, topics); err != nil {
+		return fmt.Errorf("failed to set topics of %s/%s: %w", org, repo, err)
+	}
+
+	return nil
+}
+
+// AddRepoTopics adds the topics to repo and keeps the existing ones. Nothing is
+// changed if the repo has all of them.
+func (cl client) AddRepoTopics(org, repo string, topics []string) error {
+	current, err := cl.GetRepoTopics(org, repo)
+	if err != nil {
+		return err
+	}
+
+	merged := current
+	exists := sets.NewString(current...)
+	for _, t := range topics {
+		if !exists.Has(t) {
+			exists.Insert(t)
+			merged = append(merged, t)
+		}
+	}
+
+	if len(merged) == len(current) {
+		return nil
+	}
+
+	return cl.SetRepoTopics(org, repo, merged)
+}
+
+// validateTopics checks the topics against the rules of github, so that the
+// call doesn't fail with 422.
+func validateTopics(topics []string) error {
+	if len(topics) > maxTopics {
+		return fmt.Errorf("too many topics: %d, at most %d", len(topics), maxTopics)
+	}
+
+	for _, t := range topics {
+		if len(t) > maxTopicLength {
+			return fmt.Errorf("invalid topic %q: longer than %d characters", t, maxTopicLength)
+		}
+
+		if !topicRegexp.MatchString(t) {
+			return fmt.Errorf(
+				"invalid topic %q: it must be lowercase letters, numbers and hyphens, and start with a letter or number", t,
+			)
+		}
+	}
+
+	return nil
+}
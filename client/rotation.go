@@ -0,0 +1,124 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// RotationStatus describes the hmac tokens configured for a key of the secret file.
+type RotationStatus struct {
+	// Key is the key of the secret file, such as "owner/repo", "org" or "*".
+	Key string
+	// Level is the configuration level of the key, one of repo-event, repo,
+	// repo-glob, org-event, org, installation and global.
+	Level string
+	// Tokens is the number of tokens configured.
+	Tokens int
+	// Oldest is the earliest time when the tokens are created. It is zero if
+	// none of the tokens has created_at.
+	Oldest time.Time
+	// Newest is the latest time when the tokens are created. It is zero if
+	// none of the tokens has created_at.
+	Newest time.Time
+}
+
+// Spread returns the duration between the oldest and the newest tokens.
+func (s RotationStatus) Spread() time.Duration {
+	return s.Newest.Sub(s.Oldest)
+}
+
+// HasOverlap tells whether both the old and the new tokens are configured,
+// so that the webhook secret can be flipped in GitHub without rejecting
+// the deliveries signed by either.
+func (s RotationStatus) HasOverlap() bool {
+	return s.Tokens > 1
+}
+
+// NextRotation reports the tokens configured for each key of the secret file,
+// so that operators can confirm an overlap window exists before flipping the
+// webhook secret in GitHub. The result is sorted by key. The legacy single
+// token file is reported as one token of the global level.
+func NextRotation(secretFile []byte) ([]RotationStatus, error) {
+	if isSingleToken(secretFile) {
+		return []RotationStatus{{Key: "*", Level: HmacLevelGlobal, Tokens: 1}}, nil
+	}
+
+	repoToTokenMap := map[string]hmacsForRepo{}
+	if err := yaml.Unmarshal(secretFile, &repoToTokenMap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecretFile, err)
+	}
+
+	r := make([]RotationStatus, 0, len(repoToTokenMap))
+	for key, tokens := range repoToTokenMap {
+		s := RotationStatus{Key: key, Level: levelOfKey(key), Tokens: len(tokens)}
+
+		for i := range tokens {
+			t := tokens[i].CreatedAt
+			if t.IsZero() {
+				continue
+			}
+
+			if s.Oldest.IsZero() || t.Before(s.Oldest) {
+				s.Oldest = t
+			}
+
+			if t.After(s.Newest) {
+				s.Newest = t
+			}
+		}
+
+		r = append(r, s)
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Key < r[j].Key
+	})
+
+	return r, nil
+}
+
+// levelOfKey returns the configuration level of the key of secret file.
+func levelOfKey(key string) string {
+	switch {
+	case key == "*":
+		return HmacLevelGlobal
+
+	case isInstallationKey(key):
+		return HmacLevelInstallation
+
+	case strings.Contains(key, "/"):
+		if strings.Contains(key, ":") {
+			return HmacLevelRepoEvent
+		}
+
+		if strings.ContainsAny(key, "*?[") {
+			return HmacLevelRepoGlob
+		}
+
+		return HmacLevelRepo
+
+	case strings.Contains(key, ":"):
+		return HmacLevelOrgEvent
+
+	default:
+		return HmacLevelOrg
+	}
+}
+
+// isInstallationKey tells whether the key is "installation:id". It is not the
+// event type of the org named installation, since the event type is not a number.
+func isInstallationKey(key string) bool {
+	id := strings.TrimPrefix(key, installationKeyPrefix)
+	if id == key {
+		return false
+	}
+
+	_, err := strconv.ParseInt(id, 10, 64)
+
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestNextRotation(t *testing.T) {
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	rotating := hmacsForRepo{{Value: "old", CreatedAt: old}, {Value: "new", CreatedAt: old.Add(week)}}
+
+	file, err := yaml.Marshal(map[string]hmacsForRepo{
+		"owner/repo:push": rotating,
+		"owner/infra-*":   rotating,
+		"owner:push":      rotating,
+		"installation:42": rotating,
+		"owner":           {{Value: "single", CreatedAt: old}},
+		"*":               {{Value: "global"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		file    []byte
+		want    []RotationStatus
+		overlap []bool
+		spread  []time.Duration
+	}{
+		{
+			name: "hierarchical",
+			file: file,
+			want: []RotationStatus{
+				{Key: "*", Level: HmacLevelGlobal, Tokens: 1},
+				{Key: "installation:42", Level: HmacLevelInstallation, Tokens: 2, Oldest: old, Newest: old.Add(week)},
+				{Key: "owner", Level: HmacLevelOrg, Tokens: 1, Oldest: old, Newest: old},
+				{Key: "owner/infra-*", Level: HmacLevelRepoGlob, Tokens: 2, Oldest: old, Newest: old.Add(week)},
+				{Key: "owner/repo:push", Level: HmacLevelRepoEvent, Tokens: 2, Oldest: old, Newest: old.Add(week)},
+				{Key: "owner:push", Level: HmacLevelOrgEvent, Tokens: 2, Oldest: old, Newest: old.Add(week)},
+			},
+			overlap: []bool{false, true, false, true, true, true},
+			spread:  []time.Duration{0, week, 0, week, week, week},
+		},
+		{
+			name:    "legacy single token",
+			file:    []byte("secret\n"),
+			want:    []RotationStatus{{Key: "*", Level: HmacLevelGlobal, Tokens: 1}},
+			overlap: []bool{false},
+			spread:  []time.Duration{0},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := NextRotation(c.file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got) != len(c.want) {
+				t.Fatalf("got %+v, want %+v", got, c.want)
+			}
+
+			for i := range got {
+				g, w := got[i], c.want[i]
+				if g.Key != w.Key || g.Level != w.Level || g.Tokens != w.Tokens ||
+					!g.Oldest.Equal(w.Oldest) || !g.Newest.Equal(w.Newest) {
+					t.Errorf("got %+v, want %+v", g, w)
+				}
+
+				if g.HasOverlap() != c.overlap[i] {
+					t.Errorf("got overlap %t of %s, want %t", g.HasOverlap(), g.Key, c.overlap[i])
+				}
+
+				if g.Spread() != c.spread[i] {
+					t.Errorf("got spread %v of %s, want %v", g.Spread(), g.Key, c.spread[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextRotationInvalidFile(t *testing.T) {
+	if _, err := NextRotation([]byte("a: b: c\nd")); !errors.Is(err, ErrInvalidSecretFile) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSecretFile)
+	}
+}
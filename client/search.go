@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+const (
+	// searchResultLimit is the max number of results the search API returns for a query.
+	searchResultLimit = 1000
+
+	searchMaxRetries = 3
+	searchMaxWait    = time.Minute
+)
+
+// SearchOptions is the options of searching.
+type SearchOptions struct {
+	// Sort is the field to sort by, for example "created" or "updated" for issues.
+	Sort string
+	// Order is "asc" or "desc". It is ignored if Sort is empty.
+	Order string
+	// MaxResults is the max number of results to return. It is at most 1000
+	// which is the limit of search API. Zero means the limit.
+	MaxResults int
+}
+
+// IssueSearchResult is the result of searching issues and pull requests.
+type IssueSearchResult struct {
+	// Total is the number of issues matching the query, which may be more than
+	// the issues returned.
+	Total int
+	// Incomplete means the search timed out on github and the result may be partial.
+	Incomplete bool
+	Issues     []*sdk.Issue
+}
+
+// CodeSearchResult is the result of searching code.
+type CodeSearchResult struct {
+	// Total is the number of files matching the query, which may be more than
+	// the files returned.
+	Total int
+	// Incomplete means the search timed out on github and the result may be partial.
+	Incomplete bool
+	Files      []*sdk.CodeResult
+}
+
+// SearchIssues returns the issues and pull requests matching the query. All the
+// pages are fetched until the limit of 1000 results. The search API has its own
+// rate limit, 30 requests per minute, so it waits until the limit resets if it
+// is exceeded.
+func (cl client) SearchIssues(query string, opts SearchOptions) (IssueSearchResult, error) {
+	var r IssueSearchResult
+
+	opt := opts.toSDK()
+	max := opts.maxResults()
+	for {
+		var v *sdk.IssuesSearchResult
+
+		resp, err := cl.searchWithBackoff(func(ctx context.Context) (resp *sdk.Response, err error) {
+			v, resp, err = cl.c.Search.Issues(ctx, query, opt)
+
+			return
+		})
+		if err != nil {
+			return r, fmt.Errorf("failed to search issues with %q: %w", query, err)
+		}
+
+		r.Total = v.GetTotal()
+		r.Incomplete = r.Incomplete || v.GetIncompleteResults()
+		r.Issues = append(r.Issues, v.Issues...)
+
+		if len(r.Issues) >= max {
+			r.Issues = r.Issues[:max]
+
+			break
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return r, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return r, nil
+}
+
+// SearchCode returns the files matching the query. See SearchIssues for
+// the pagination and rate limit.
+func (cl client) SearchCode(query string, opts SearchOptions) (CodeSearchResult, error) {
+	var r CodeSearchResult
+
+	opt := opts.toSDK()
+	max := opts.maxResults()
+	for {
+		var v *sdk.CodeSearchResult
+
+		resp, err := cl.searchWithBackoff(func(ctx context.Context) (resp *sdk.Response, err error) {
+			v, resp, err = cl.c.Search.Code(ctx, query, opt)
+
+			return
+		})
+		if err != nil {
+			return r, fmt.Errorf("failed to search code with %q: %w", query, err)
+		}
+
+		r.Total = v.GetTotal()
+		r.Incomplete = r.Incomplete || v.GetIncompleteResults()
+		r.Files = append(r.Files, v.CodeResults...)
+
+		if len(r.Files) >= max {
+			r.Files = r.Files[:max]
+
+			break
+		}
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return r, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return r, nil
+}
+
+func (opts SearchOptions) toSDK() *sdk.SearchOptions {
+	opt := &sdk.SearchOptions{
+		Sort:        opts.Sort,
+		ListOptions: sdk.ListOptions{Page: 1, PerPage: 100},
+	}
+
+	if opts.Sort != "" {
+		opt.Order = opts.Order
+	}
+
+	return opt
+}
+
+func (opts SearchOptions) maxResults() int {
+	if opts.MaxResults <= 0 || opts.MaxResults > searchResultLimit {
+		return searchResultLimit
+	}
+
+	return opts.MaxResults
+}
+
+// searchWithBackoff calls the search API and retries it after the rate limit
+// resets if the limit is exceeded. Each call has its own timeout, so that
+// waiting doesn't consume it.
+func (cl client) searchWithBackoff(do func(context.Context) (*sdk.Response, error)) (*sdk.Response, error) {
+	for i := 0; ; i++ {
+		ctx, cancel := cl.newContext()
+		resp, err := do(ctx)
+		cancel()
+
+		wait, ok := searchRetryAfter(err)
+		if !ok || i >= searchMaxRetries {
+			return resp, err
+		}
+
+		time.Sleep(wait)
+	}
+}
+
+// searchRetryAfter returns how long to wait before retrying if the err is
+// caused by exceeding the rate limit.
+func searchRetryAfter(err error) (time.Duration, bool) {
+	var wait time.Duration
+
+	var rateErr *sdk.RateLimitError
+	var abuseErr *sdk.AbuseRateLimitError
+
+	switch {
+	case errors.As(err, &rateErr):
+		wait = time.Until(rateErr.Rate.Reset.Time) + time.Second
+
+	case errors.As(err, &abuseErr):
+		wait = abuseErr.GetRetryAfter()
+
+	default:
+		return 0, false
+	}
+
+	switch {
+	case wait <= 0:
+		wait = time.Second
+
+	case wait > searchMaxWait:
+		wait = searchMaxWait
+	}
+
+	return wait, true
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// NewFileTokenGenerator returns the token generator which returns the content of
+// the file at path. The file is reloaded every reload interval, so that the rotated
+// secret takes effect without restart. The last content is kept if the file can't
+// be read, for example it is being deleted and recreated. The reloading stops
+// when ctx is done.
+func NewFileTokenGenerator(ctx context.Context, path string, reload time.Duration) (func() []byte, error) {
+	if reload <= 0 {
+		return nil, errors.New("the reload interval must be positive")
+	}
+
+	v, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the secret file %s: %w", path, err)
+	}
+
+	s := &fileSecret{path: path, content: v}
+
+	go s.watch(ctx, reload)
+
+	return s.get, nil
+}
+
+// fileSecret caches the content of secret file.
+type fileSecret struct {
+	lock sync.RWMutex
+
+	path    string
+	content []byte
+}
+
+func (s *fileSecret) get() []byte {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.content
+}
+
+// watch reloads the file every interval until ctx is done.
+func (s *fileSecret) watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-t.C:
+			s.reload()
+		}
+	}
+}
+
+func (s *fileSecret) reload() {
+	v, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		defaultLogger.Error("failed to reload the secret file", LogFields{
+			"path":  s.path,
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if len(v) == 0 {
+		// The file may be recreated but not written yet.
+		return
+	}
+
+	s.lock.Lock()
+	s.content = v
+	s.lock.Unlock()
+}
+
+// GenerateSecret returns a random webhook secret of n bytes which is hex encoded.
+func GenerateSecret(n int) (string, error) {
+	if n <= 0 {
+		return "", errors.New("the size of secret must be positive")
+	}
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// BuildSecretFile returns the content of hierarchical hmac secret file which
+// has a token created now for each key. The key is "owner/repo", "org", "*"
+// or the others supported by the secret file.
+func BuildSecretFile(entries map[string]string) ([]byte, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m := make(map[string]hmacsForRepo, len(entries))
+	for k, v := range entries {
+		if k == "" {
+			return nil, errors.New("the key of secret is empty")
+		}
+
+		if v == "" {
+			return nil, fmt.Errorf("the secret for %s is empty", k)
+		}
+
+		m[k] = hmacsForRepo{{Value: v, CreatedAt: now}}
+	}
+
+	return yaml.Marshal(m)
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+)
+
+// ValidateReader is the same as ValidateEventCtx except that the payload is read
+// from r, and it is not kept in memory. The hmacs of all the tokens in the secret
+// file are computed while reading, and only the fields of payload needed to select
+// the tokens are decoded, so that the peak memory is bounded for the large payload.
+// At most the max payload size bytes are read, see WithMaxPayloadSize.
+func (v *Validator) ValidateReader(ctx context.Context, eventType string, r io.Reader, sig string) (HmacMatch, error) {
+	cr := &countingReader{r: io.LimitReader(r, v.maxPayloadSize+1)}
+
+	m, err := v.validateReader(ctx, eventType, cr, sig)
+
+	v.metrics.Received(int(cr.n))
+	if err != nil {
+		v.metrics.Failed(failureReason(err))
+	} else {
+		v.metrics.Passed()
+	}
+
+	return m, err
+}
+
+func (v *Validator) validateReader(ctx context.Context, eventType string, r *countingReader, sig string) (HmacMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return HmacMatch{}, err
+	}
+
+	hashFunc, sb, err := decodeSignature(sig)
+	if err != nil {
+		return HmacMatch{}, err
+	}
+
+	// The owner is unknown until the payload is read, so the tokens of all
+	// the generators are candidates. The hmacs are selected from the same
+	// contents of secret files later, even if they are rotated meanwhile.
+	v = v.snapshot()
+
+	var tokens []string
+	if v.tokenGenerator != nil {
+		tokens = v.candidateTokens(v.tokenGenerator())
+	}
+
+	for _, g := range v.tokenGenerators {
+		tokens = append(tokens, v.candidateTokens(g())...)
+	}
+
+	macs := map[string]hash.Hash{}
+	writers := []io.Writer{}
+	for _, t := range tokens {
+		if _, ok := macs[t]; !ok {
+			mac := hmac.New(hashFunc, []byte(t))
+			macs[t] = mac
+			writers = append(writers, mac)
+		}
+	}
+
+	tee := io.TeeReader(r, io.MultiWriter(writers...))
+
+	// The BOM is skipped after the hmacs are fed, see parseGenericEvent.
+	br := bufio.NewReader(tee)
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	event, err := decodeGenericEvent(json.NewDecoder(br))
+	if err == nil {
+		// The trailing bytes are part of the signed payload too.
+		_, err = io.Copy(ioutil.Discard, br)
+	}
+
+	if r.n > v.maxPayloadSize {
+		return HmacMatch{}, ErrPayloadTooLarge
+	}
+
+	if err != nil {
+		v.logger.Info(
+			"validatePayload couldn't decode the github event payload",
+			LogFields{"error": err.Error()},
+		)
+
+		return HmacMatch{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return HmacMatch{}, err
+	}
+
+	sets, err := v.hmacsFor(event.owner(), event.installationKey(), eventType)
+	if err != nil {
+		v.logger.Error("couldn't unmarshal the hmac secret", LogFields{"error": err.Error()})
+
+		return HmacMatch{}, err
+	}
+
+	return matchHmacs(sb, sets, func(key []byte) []byte {
+		if mac, ok := macs[string(key)]; ok {
+			return mac.Sum(nil)
+		}
+
+		return nil
+	})
+}
+
+// snapshot returns a copy of Validator of which the token generators return
+// the contents of secret files read by the generators of v once.
+func (v *Validator) snapshot() *Validator {
+	c := *v
+
+	if v.tokenGenerator != nil {
+		c.tokenGenerator = fixedTokenGenerator(v.tokenGenerator())
+	}
+
+	c.tokenGenerators = make(map[string]func() []byte, len(v.tokenGenerators))
+	for k, g := range v.tokenGenerators {
+		c.tokenGenerators[k] = fixedTokenGenerator(g())
+	}
+
+	return &c
+}
+
+func fixedTokenGenerator(t []byte) func() []byte {
+	return func() []byte { return t }
+}
+
+// candidateTokens returns all the tokens in the secret file, which may be
+// the legacy single token.
+func (v *Validator) candidateTokens(t []byte) []string {
+	repoToTokenMap, err := v.secrets.parse(t)
+	if err != nil {
+		return []string{string(t)}
+	}
+
+	var r []string
+	for _, tokens := range repoToTokenMap {
+		for i := range tokens {
+			r = append(r, tokens[i].Value)
+		}
+	}
+
+	return r
+}
+
+// decodeGenericEvent decodes the fields of genericEvent from the top level object,
+// and skips the others without keeping them.
+func decodeGenericEvent(dec *json.Decoder) (genericEvent, error) {
+	var e genericEvent
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return e, err
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return e, err
+		}
+
+		switch t {
+		case "sender":
+			err = dec.Decode(&e.Sender)
+
+		case "repository":
+			err = dec.Decode(&e.Repo)
+
+		case "organization":
+			err = dec.Decode(&e.Org)
+
+		case "installation":
+			err = dec.Decode(&e.Installation)
+
+		default:
+			err = skipJSONValue(dec)
+		}
+
+		if err != nil {
+			return e, err
+		}
+	}
+
+	return e, expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if t != d {
+		return fmt.Errorf("expect %s but got %v", d, t)
+	}
+
+	return nil
+}
+
+// skipJSONValue skips the next value which may be an object or array.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if d, ok := t.(json.Delim); ok {
+			if d == '{' || d == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const streamSecretFile = `
+"owner/repo": [{value: repo}]
+"owner/repo:push": [{value: push}]
+"owner": [{value: org}]
+"*": [{value: global}]
+`
+
+func TestValidateReader(t *testing.T) {
+	repoPayload := `{"action":"opened","sender":{"login":"a"},"repository":{"full_name":"owner/repo","topics":["x"]}}`
+
+	cases := []struct {
+		name      string
+		eventType string
+		payload   string
+		token     string
+		maxSize   int64
+		level     string
+		wantErr   error
+	}{
+		{name: "repo", payload: repoPayload, token: "repo", level: HmacLevelRepo},
+		{name: "event of repo", eventType: "push", payload: repoPayload, token: "push", level: HmacLevelRepoEvent},
+		{name: "org", payload: `{"organization":{"login":"owner"}}`, token: "org", level: HmacLevelOrg},
+		{name: "ping", payload: `{"zen":"z","hook":{"config":{"url":"u"}}}`, token: "global", level: HmacLevelGlobal},
+		{name: "token of other level", payload: repoPayload, token: "org", wantErr: ErrSignatureMismatch},
+		{name: "unknown token", payload: repoPayload, token: "unknown", wantErr: ErrSignatureMismatch},
+		{name: "invalid json", payload: `{"repository":`, token: "global", wantErr: ErrInvalidPayload},
+		{name: "too large", payload: repoPayload, token: "repo", maxSize: 10, wantErr: ErrPayloadTooLarge},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := []Option{WithTokenGenerator(func() []byte { return []byte(streamSecretFile) })}
+			if c.maxSize > 0 {
+				opts = append(opts, WithMaxPayloadSize(c.maxSize))
+			}
+
+			v := NewValidator(opts...)
+			sig := PayloadSignature256([]byte(c.payload), []byte(c.token))
+
+			m, err := v.ValidateReader(context.Background(), c.eventType, strings.NewReader(c.payload), sig)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("got error %v, want %v", err, c.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if m.Level != c.level {
+				t.Errorf("got level %s, want %s", m.Level, c.level)
+			}
+
+			// It must agree with the in-memory validation.
+			if m1, err := v.ValidateEvent(c.eventType, []byte(c.payload), sig); err != nil || m1 != m {
+				t.Errorf("got %+v, %v by ValidateEvent, want %+v", m1, err, m)
+			}
+		})
+	}
+}
+
+// largePayload returns a payload of which the repository comes first, followed
+// by a large diff.
+func TestValidateReaderReadsSecretFileOnce(t *testing.T) {
+	payload := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+
+	// The secret file is rotated after each read.
+	files := []string{"\"*\":\n  - value: old\n", "\"*\":\n  - value: new\n"}
+	reads := 0
+	gen := func() []byte {
+		f := files[reads%len(files)]
+		reads++
+
+		return []byte(f)
+	}
+
+	v := NewValidator(WithTokenGenerator(gen), WithGlobalFallbackAlways(true))
+
+	m, err := v.ValidateReader(context.Background(), "", bytes.NewReader(payload), PayloadSignature256(payload, []byte("old")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.Level != HmacLevelGlobal {
+		t.Errorf("got level %s, want %s", m.Level, HmacLevelGlobal)
+	}
+
+	if reads != 1 {
+		t.Errorf("got %d reads of secret file, want 1", reads)
+	}
+}
+
+func largePayload() []byte {
+	b := new(bytes.Buffer)
+	b.WriteString(`{"repository":{"full_name":"owner/repo"},"files":[`)
+
+	for i := 0; i < 20000; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		fmt.Fprintf(b, `{"filename":"dir/file-%d.go","patch":"@@ -1,3 +1,3 @@ some changes"}`, i)
+	}
+
+	b.WriteString("]}")
+
+	return b.Bytes()
+}
+
+func BenchmarkValidateReader(b *testing.B) {
+	payload := largePayload()
+	sig := PayloadSignature256(payload, []byte("repo"))
+	v := NewValidator(WithTokenGenerator(func() []byte { return []byte(streamSecretFile) }))
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := v.ValidateReader(context.Background(), "", bytes.NewReader(payload), sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateEvent(b *testing.B) {
+	payload := largePayload()
+	sig := PayloadSignature256(payload, []byte("repo"))
+	v := NewValidator(WithTokenGenerator(func() []byte { return []byte(streamSecretFile) }))
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := v.ValidateEvent("", payload, sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+const (
+	// MembershipActive means the user is a member.
+	MembershipActive = "active"
+	// MembershipPending means the user is invited but has not accepted it yet.
+	MembershipPending = "pending"
+)
+
+// GetTeamMembership returns the membership state of user in the team specified
+// by its slug, which is MembershipActive, MembershipPending or empty if the user
+// is not a member.
+func (cl client) GetTeamMembership(org, teamSlug, user string) (string, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	m, r, err := cl.c.Teams.GetTeamMembershipBySlug(ctx, org, teamSlug, user)
+	if err != nil {
+		if r != nil && r.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to get membership of %s in team %s/%s: %w", user, org, teamSlug, err)
+	}
+
+	return m.GetState(), nil
+}
+
+// IsTeamMember tells whether the user is an active member of the team. The user
+// who is invited but has not accepted it is not. See GetTeamMembership.
+func (cl client) IsTeamMember(org, teamSlug, user string) (bool, error) {
+	state, err := cl.GetTeamMembership(org, teamSlug, user)
+	if err != nil {
+		return false, err
+	}
+
+	return state == MembershipActive, nil
+}
+
+// ListTeamRepos returns all the repos which the team specified by its slug has
+// access to. The permissions of team are in the Permissions of repo.
+func (cl client) ListTeamRepos(org, teamSlug string) ([]*sdk.Repository, error) {
+	ctx, cancel := cl.newContext()
+	defer cancel()
+
+	var repos []*sdk.Repository
+
+	opt := &sdk.ListOptions{Page: 1, PerPage: 100}
+	for {
+		v, resp, err := cl.c.Teams.ListTeamReposBySlug(ctx, org, teamSlug, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos of team %s/%s: %w", org, teamSlug, err)
+		}
+
+		repos = append(repos, v...)
+
+		page, err := nextPage(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if page == 0 {
+			break
+		}
+
+		opt.Page = page
+	}
+
+	return repos, nil
+}
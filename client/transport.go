@@ -0,0 +1,268 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/google/go-github/v36/github"
+)
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRateResource  = "X-RateLimit-Resource"
+)
+
+// rateRecorder records the rate limit of core API carried by the latest response.
+type rateRecorder struct {
+	lock sync.RWMutex
+
+	rate sdk.Rate
+	seen bool
+}
+
+func (r *rateRecorder) get() (sdk.Rate, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.rate, r.seen
+}
+
+func (r *rateRecorder) record(resp *http.Response) {
+	limit := resp.Header.Get(headerRateLimit)
+	if limit == "" {
+		return
+	}
+
+	// The search and graphql APIs have their own rate limits.
+	if v := resp.Header.Get(headerRateResource); v != "" && v != "core" {
+		return
+	}
+
+	rate := sdk.Rate{}
+	rate.Limit, _ = strconv.Atoi(limit)
+	rate.Remaining, _ = strconv.Atoi(resp.Header.Get(headerRateRemaining))
+	if v, err := strconv.ParseInt(resp.Header.Get(headerRateReset), 10, 64); err == nil {
+		rate.Reset = sdk.Timestamp{Time: time.Unix(v, 0)}
+	}
+
+	r.lock.Lock()
+	r.rate = rate
+	r.seen = true
+	r.lock.Unlock()
+}
+
+// rateLimitTransport records the rate limit headers of every response.
+type rateLimitTransport struct {
+	base     http.RoundTripper
+	recorder *rateRecorder
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.recorder.record(resp)
+	}
+
+	return resp, err
+}
+
+// defaultRetryMaxDelay is the default max delay between the retries with
+// exponential backoff, see WithMaxDelay.
+const defaultRetryMaxDelay = time.Minute
+
+// CallOption configures the retry of the calls of client. See Client.WithCallOptions.
+type CallOption func(*retryPolicy)
+
+// WithMaxAttempts sets the max attempts of a call, 1 means no retry.
+func WithMaxAttempts(n int) CallOption {
+	return func(p *retryPolicy) {
+		p.maxAttempts = n
+	}
+}
+
+// WithBaseDelay sets the delay before the first retry, which grows exponentially.
+func WithBaseDelay(d time.Duration) CallOption {
+	return func(p *retryPolicy) {
+		p.baseDelay = d
+	}
+}
+
+// WithMaxDelay sets the max delay between the retries, which bounds the
+// exponential growth of delay. It is a minute by default. The delay asked
+// by the Retry-After header is not bounded by it, see WithMaxElapsed.
+func WithMaxDelay(d time.Duration) CallOption {
+	return func(p *retryPolicy) {
+		p.maxDelay = d
+	}
+}
+
+// WithMaxElapsed sets the max time spent on a call including the retries.
+// No retry is made if it would exceed the max elapsed time. Zero means no limit
+// other than the timeout of call.
+func WithMaxElapsed(d time.Duration) CallOption {
+	return func(p *retryPolicy) {
+		p.maxElapsed = d
+	}
+}
+
+// retryPolicy is the configuration of retry.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	maxElapsed  time.Duration
+}
+
+type callOptionsKey struct{}
+
+// withCallOptions returns the context carrying the call options.
+func withCallOptions(ctx context.Context, opts []CallOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, callOptionsKey{}, opts)
+}
+
+// retryTransport retries the request on the secondary rate limit and
+// the transient server errors. The policy can be overridden by the call
+// options carried by the context of request.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy retryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.policy
+	if opts, ok := req.Context().Value(callOptionsKey{}).([]CallOption); ok {
+		for _, opt := range opts {
+			opt(&p)
+		}
+	}
+
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= p.maxAttempts {
+			return resp, err
+		}
+
+		delay, retry := p.retryDelay(req, resp, attempt)
+		if !retry {
+			return resp, nil
+		}
+
+		if p.maxElapsed > 0 && time.Since(start)+delay > p.maxElapsed {
+			return resp, nil
+		}
+
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay tells whether to retry the request and how long to wait before that.
+// The request which is not idempotent, such as creating a comment, is retried
+// only if github rejects it because of the rate limit, since the other failures
+// don't prove that it has not been done.
+func (p *retryPolicy) retryDelay(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if isIdempotent(req) {
+			return p.backoff(attempt), true
+		}
+
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if v := resp.Header.Get("Retry-After"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				return time.Duration(n) * time.Second, true
+			}
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+			return p.backoff(attempt), true
+		}
+	}
+
+	return 0, false
+}
+
+// backoff returns the exponential delay with jitter for the attempt, which is
+// at most the max delay, see WithMaxDelay.
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	d := p.maxDelay
+	if d <= 0 {
+		d = defaultRetryMaxDelay
+	}
+
+	// The shift is checked against the max delay, so that it doesn't overflow.
+	if shift := attempt - 1; shift >= 0 && shift < 63 && p.baseDelay <= d>>uint(shift) {
+		d = p.baseDelay << uint(shift)
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isIdempotent tells whether sending the request more than once has the same
+// effect as sending it once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch:
+		return false
+	}
+
+	return true
+}
+
+// isSecondaryRateLimit tells whether the 403 response is caused by the
+// secondary rate limit. The body of response is kept readable.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	if err != nil {
+		return false
+	}
+
+	msg := strings.ToLower(string(b))
+
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse")
+}
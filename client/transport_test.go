@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTransport responds the requests with the statuses in order, and the last
+// one for the rest.
+type fakeTransport struct {
+	statuses []int
+	header   http.Header
+	calls    int
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := t.statuses[len(t.statuses)-1]
+	if t.calls < len(t.statuses) {
+		status = t.statuses[t.calls]
+	}
+
+	t.calls++
+
+	header := http.Header{}
+	if status != http.StatusOK {
+		for k, v := range t.header {
+			header[k] = v
+		}
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		name        string
+		method      string
+		statuses    []int
+		header      http.Header
+		maxAttempts int
+		wantStatus  int
+		wantCalls   int
+	}{
+		{
+			name:        "503 twice then 200",
+			method:      http.MethodGet,
+			statuses:    []int{503, 503, 200},
+			maxAttempts: 3,
+			wantStatus:  200,
+			wantCalls:   3,
+		},
+		{
+			name:        "max attempts reached",
+			method:      http.MethodGet,
+			statuses:    []int{503, 503, 200},
+			maxAttempts: 2,
+			wantStatus:  503,
+			wantCalls:   2,
+		},
+		{
+			name:        "retry disabled",
+			method:      http.MethodGet,
+			statuses:    []int{503, 200},
+			maxAttempts: 0,
+			wantStatus:  503,
+			wantCalls:   1,
+		},
+		{
+			name:        "post is not retried on 503",
+			method:      http.MethodPost,
+			statuses:    []int{503, 200},
+			maxAttempts: 3,
+			wantStatus:  503,
+			wantCalls:   1,
+		},
+		{
+			name:        "post is retried on the rate limit",
+			method:      http.MethodPost,
+			statuses:    []int{403, 200},
+			header:      http.Header{"Retry-After": []string{"0"}},
+			maxAttempts: 3,
+			wantStatus:  200,
+			wantCalls:   2,
+		},
+		{
+			name:        "403 without rate limit is not retried",
+			method:      http.MethodGet,
+			statuses:    []int{403, 200},
+			maxAttempts: 3,
+			wantStatus:  403,
+			wantCalls:   1,
+		},
+		{
+			name:        "404 is not retried",
+			method:      http.MethodGet,
+			statuses:    []int{404, 200},
+			maxAttempts: 3,
+			wantStatus:  404,
+			wantCalls:   1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			base := &fakeTransport{statuses: c.statuses, header: c.header}
+			rt := &retryTransport{
+				base:   base,
+				policy: retryPolicy{maxAttempts: c.maxAttempts, baseDelay: time.Millisecond},
+			}
+
+			req, err := http.NewRequest(c.method, "https://api.github.com/repos/owner/repo", strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.StatusCode != c.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, c.wantStatus)
+			}
+
+			if base.calls != c.wantCalls {
+				t.Errorf("got %d calls, want %d", base.calls, c.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransportStopsWhenContextIsDone(t *testing.T) {
+	base := &fakeTransport{statuses: []int{503}}
+	rt := &retryTransport{
+		base:   base,
+		policy: retryPolicy{maxAttempts: 3, baseDelay: time.Hour},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/owner/repo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rt.RoundTrip(req); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if base.calls != 1 {
+		t.Errorf("got %d calls, want 1", base.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  retryPolicy
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			name:    "first attempt",
+			policy:  retryPolicy{baseDelay: time.Second},
+			attempt: 1,
+			min:     time.Second / 2,
+			max:     time.Second,
+		},
+		{
+			name:    "grows exponentially",
+			policy:  retryPolicy{baseDelay: time.Second},
+			attempt: 3,
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			name:    "capped by the default max delay",
+			policy:  retryPolicy{baseDelay: time.Second},
+			attempt: 20,
+			min:     defaultRetryMaxDelay / 2,
+			max:     defaultRetryMaxDelay,
+		},
+		{
+			name:    "zero base delay",
+			policy:  retryPolicy{},
+			attempt: 5,
+			min:     0,
+			max:     0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if d := c.policy.backoff(c.attempt); d < c.min || d > c.max {
+					t.Fatalf("got delay %v, want in [%v, %v]", d, c.min, c.max)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffAtHighAttempts(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy retryPolicy
+		max    time.Duration
+	}{
+		{name: "default max delay", policy: retryPolicy{baseDelay: time.Second}, max: defaultRetryMaxDelay},
+		{name: "max delay of call", policy: retryPolicy{baseDelay: time.Second, maxDelay: 5 * time.Second}, max: 5 * time.Second},
+		{name: "huge base delay", policy: retryPolicy{baseDelay: 1 << 62, maxDelay: time.Second}, max: time.Second},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, attempt := range []int{30, 63, 64, 65, 100, 1000, 1 << 30} {
+				if d := c.policy.backoff(attempt); d < 0 || d > c.max {
+					t.Fatalf("got delay %v at attempt %d, want in [0, %v]", d, attempt, c.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportTakesCallOptions(t *testing.T) {
+	cases := []struct {
+		name      string
+		opts      []CallOption
+		wantCalls int
+	}{
+		{name: "client default", wantCalls: 3},
+		{name: "fewer attempts", opts: []CallOption{WithMaxAttempts(2)}, wantCalls: 2},
+		{name: "no retry", opts: []CallOption{WithMaxAttempts(1)}, wantCalls: 1},
+		{
+			name:      "max elapsed exceeded",
+			opts:      []CallOption{WithBaseDelay(time.Hour), WithMaxDelay(time.Hour), WithMaxElapsed(time.Minute)},
+			wantCalls: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			base := &fakeTransport{statuses: []int{503}}
+			rt := &retryTransport{
+				base:   base,
+				policy: retryPolicy{maxAttempts: 3, baseDelay: time.Millisecond},
+			}
+
+			ctx := withCallOptions(context.Background(), c.opts)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/owner/repo", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := rt.RoundTrip(req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if base.calls != c.wantCalls {
+				t.Errorf("got %d calls, want %d", base.calls, c.wantCalls)
+			}
+		})
+	}
+}
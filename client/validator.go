@@ -0,0 +1,464 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// AlgorithmSHA1 means the signature is in X-Hub-Signature header.
+	AlgorithmSHA1 = "sha1"
+	// AlgorithmSHA256 means the signature is in X-Hub-Signature-256 header.
+	AlgorithmSHA256 = "sha256"
+)
+
+// HeaderNames are the names of headers of webhook request.
+type HeaderNames struct {
+	// Signature256 is the header of sha256 signature, X-Hub-Signature-256 by default.
+	Signature256 string
+	// Signature is the header of sha1 signature, X-Hub-Signature by default.
+	Signature string
+	// Event is the header of event type, X-GitHub-Event by default.
+	Event string
+	// Delivery is the header of delivery guid, X-GitHub-Delivery by default.
+	Delivery string
+}
+
+// DefaultHeaderNames are the names of headers which github sends.
+var DefaultHeaderNames = HeaderNames{
+	Signature256: "X-Hub-Signature-256",
+	Signature:    "X-Hub-Signature",
+	Event:        "X-GitHub-Event",
+	Delivery:     "X-GitHub-Delivery",
+}
+
+// withDefaults returns the names in which the empty ones are the defaults.
+func (h HeaderNames) withDefaults() HeaderNames {
+	if h.Signature256 == "" {
+		h.Signature256 = DefaultHeaderNames.Signature256
+	}
+
+	if h.Signature == "" {
+		h.Signature = DefaultHeaderNames.Signature
+	}
+
+	if h.Event == "" {
+		h.Event = DefaultHeaderNames.Event
+	}
+
+	if h.Delivery == "" {
+		h.Delivery = DefaultHeaderNames.Delivery
+	}
+
+	return h
+}
+
+// Option configures the Validator.
+type Option func(*Validator)
+
+// WithTokenGenerator sets the generator which returns the content of hmac secret file.
+func WithTokenGenerator(tokenGenerator func() []byte) Option {
+	return func(v *Validator) {
+		v.tokenGenerator = tokenGenerator
+	}
+}
+
+// WithRepoTokenGenerator sets the generator of hmac secret file for the repo,
+// org or installation of GitHub App, which is "owner/repo", "org" or
+// "installation:id". It takes precedence over the one set by WithTokenGenerator,
+// so that each tenant can manage its own secret file. The generator of repo is
+// preferred to the one of org, which is preferred to the one of installation.
+func WithRepoTokenGenerator(key string, tokenGenerator func() []byte) Option {
+	return func(v *Validator) {
+		if v.tokenGenerators == nil {
+			v.tokenGenerators = map[string]func() []byte{}
+		}
+
+		v.tokenGenerators[key] = tokenGenerator
+	}
+}
+
+// WithAlgorithm sets the preferred algorithm of signature when validating a request.
+// The signature of the other algorithm will be used if the preferred one is missing.
+func WithAlgorithm(algorithm string) Option {
+	return func(v *Validator) {
+		v.algorithm = algorithm
+	}
+}
+
+// WithMaxTokenAge sets the max age of hmac token. The token created earlier than
+// that will not be accepted any more. Zero, the default, means the token never
+// expires. The token without created_at never expires either.
+func WithMaxTokenAge(maxAge time.Duration) Option {
+	return func(v *Validator) {
+		v.maxTokenAge = maxAge
+	}
+}
+
+// WithMaxPayloadSize sets the max size of payload read from a request. See MaxPayloadSize.
+func WithMaxPayloadSize(size int64) Option {
+	return func(v *Validator) {
+		v.maxPayloadSize = size
+	}
+}
+
+// WithLogger sets the logger of Validator. See SetLogger.
+func WithLogger(l Logger) Option {
+	return func(v *Validator) {
+		if l != nil {
+			v.logger = l
+		}
+	}
+}
+
+// WithMetrics sets the collector of validation outcomes.
+func WithMetrics(m MetricsCollector) Option {
+	return func(v *Validator) {
+		if m != nil {
+			v.metrics = m
+		}
+	}
+}
+
+// WithHeaderNames sets the names of headers of webhook request, for example they
+// are renamed by the proxy. The empty names take the defaults. See DefaultHeaderNames.
+func WithHeaderNames(h HeaderNames) Option {
+	return func(v *Validator) {
+		v.headers = h.withDefaults()
+	}
+}
+
+// WithGlobalFallbackAlways makes the global "*" tokens always tried after the
+// tokens of the most specific level, which is useful when migrating the repos
+// from the global tokens to their own ones. By default, the global tokens are
+// tried only if no other level is configured for the repo.
+//
+// It weakens the isolation of tenants: anyone who knows a global token can sign
+// the webhooks of every repo, even the repo which has its own tokens.
+func WithGlobalFallbackAlways(enabled bool) Option {
+	return func(v *Validator) {
+		v.globalFallback = enabled
+	}
+}
+
+// Validator validates the payload of webhook with the configured hmacs.
+type Validator struct {
+	tokenGenerator  func() []byte
+	tokenGenerators map[string]func() []byte
+	algorithm       string
+	maxTokenAge     time.Duration
+	maxPayloadSize  int64
+	logger          Logger
+	metrics         MetricsCollector
+	headers         HeaderNames
+	globalFallback  bool
+	secrets         *hmacSecretCache
+}
+
+// NewValidator returns a Validator. The options which are not set take the
+// package level defaults.
+func NewValidator(opts ...Option) *Validator {
+	v := &Validator{
+		algorithm:      AlgorithmSHA256,
+		maxPayloadSize: MaxPayloadSize,
+		logger:         defaultLogger,
+		metrics:        nopMetricsCollector{},
+		headers:        DefaultHeaderNames,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.secrets == nil {
+		v.secrets = newHmacSecretCache(secretCacheSize(len(v.tokenGenerators)))
+	}
+
+	return v
+}
+
+// defaultSecrets is the cache of secret files shared by the package level
+// functions, such as ValidatePayload, which create a Validator for each call.
+var defaultSecrets = newHmacSecretCache(defaultSecretCacheSize)
+
+// withSecretCache sets the cache of parsed secret files.
+func withSecretCache(c *hmacSecretCache) Option {
+	return func(v *Validator) {
+		v.secrets = c
+	}
+}
+
+func newDefaultValidator(tokenGenerator func() []byte) *Validator {
+	return NewValidator(WithTokenGenerator(tokenGenerator), withSecretCache(defaultSecrets))
+}
+
+// Validate ensures that the payload signature matches the hmacs.
+func (v *Validator) Validate(payload []byte, sig string) error {
+	_, err := v.ValidateWithMatch(payload, sig)
+
+	return err
+}
+
+// ValidateWithMatch ensures that the payload signature matches the hmacs
+// and returns the hmac which the signature matches.
+func (v *Validator) ValidateWithMatch(payload []byte, sig string) (HmacMatch, error) {
+	return v.ValidateEvent("", payload, sig)
+}
+
+// ValidateEvent is the same as ValidateWithMatch except that the hmacs
+// configured for the event type are preferred. See extractHmacs.
+func (v *Validator) ValidateEvent(eventType string, payload []byte, sig string) (HmacMatch, error) {
+	return v.ValidateEventCtx(context.Background(), eventType, payload, sig)
+}
+
+// ValidateEventCtx is the same as ValidateEvent except that it stops
+// when the ctx is done.
+func (v *Validator) ValidateEventCtx(ctx context.Context, eventType string, payload []byte, sig string) (HmacMatch, error) {
+	return v.validateSigned(ctx, eventType, payload, payload, sig)
+}
+
+// validateSigned validates the signature of body which is signed by github,
+// where the event is payload. They are different if the webhook is delivered
+// as a form.
+func (v *Validator) validateSigned(ctx context.Context, eventType string, body, payload []byte, sig string) (HmacMatch, error) {
+	v.metrics.Received(len(body))
+
+	m, err := v.validateEvent(ctx, eventType, body, payload, sig)
+	if err != nil {
+		v.metrics.Failed(failureReason(err))
+	} else {
+		v.metrics.Passed()
+	}
+
+	return m, err
+}
+
+func (v *Validator) validateEvent(ctx context.Context, eventType string, body, payload []byte, sig string) (HmacMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return HmacMatch{}, err
+	}
+
+	event, err := parseGenericEvent(payload)
+	if err != nil {
+		v.logger.Info(
+			"validatePayload couldn't unmarshal the github event payload",
+			LogFields{"error": err.Error()},
+		)
+
+		return HmacMatch{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	hashFunc, sb, err := decodeSignature(sig)
+	if err != nil {
+		return HmacMatch{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return HmacMatch{}, err
+	}
+
+	sets, err := v.hmacsFor(event.owner(), event.installationKey(), eventType)
+	if err != nil {
+		v.logger.Error("couldn't unmarshal the hmac secret", LogFields{"error": err.Error()})
+
+		return HmacMatch{}, err
+	}
+
+	return matchHmacs(sb, sets, func(key []byte) []byte {
+		mac := hmac.New(hashFunc, key)
+		mac.Write(body)
+
+		return mac.Sum(nil)
+	})
+}
+
+// levelHmacs is the hmacs configured at a level.
+type levelHmacs struct {
+	level   string
+	secrets hmacsForRepo
+}
+
+// hmacsFor returns the hmacs to try for the owner and the installation, which are
+// those of the most specific level, followed by the global ones if
+// WithGlobalFallbackAlways is set. See extractHmacs. The secret file is read
+// once, so that both levels are of the same content even if it is rotating.
+func (v *Validator) hmacsFor(owner, installation, eventType string) ([]levelHmacs, error) {
+	t := v.tokenGeneratorFor(owner, installation)()
+
+	level, secrets, err := extractHmacs(owner, installation, eventType, t, v.secrets, v.maxTokenAge, v.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []levelHmacs{{level: level, secrets: secrets}}
+	if !v.globalFallback || level == HmacLevelGlobal {
+		return sets, nil
+	}
+
+	// Only "*" is tried for the empty owner, and it fails if "*" is not configured.
+	if _, globals, err := extractHmacs("", "", "", t, v.secrets, v.maxTokenAge, v.logger); err == nil {
+		sets = append(sets, levelHmacs{level: HmacLevelGlobal, secrets: globals})
+	}
+
+	return sets, nil
+}
+
+// tokenGeneratorFor returns the token generator for the owner which is the
+// full name of repo or the name of org, or for the installation if neither is
+// configured. See WithRepoTokenGenerator.
+func (v *Validator) tokenGeneratorFor(owner, installation string) func() []byte {
+	if g, ok := v.tokenGenerators[owner]; ok {
+		return g
+	}
+
+	if g, ok := v.tokenGenerators[strings.Split(owner, "/")[0]]; ok {
+		return g
+	}
+
+	if g, ok := v.tokenGenerators[installation]; ok && installation != "" {
+		return g
+	}
+
+	if v.tokenGenerator == nil {
+		return func() []byte { return nil }
+	}
+
+	return v.tokenGenerator
+}
+
+// decodeSignature returns the hash function and the digest of signature.
+func decodeSignature(sig string) (func() hash.Hash, []byte, error) {
+	hashFunc, sig := parseSignature(sig)
+	if hashFunc == nil || sig == "" || len(sig)%2 != 0 {
+		return nil, nil, ErrBadSignatureFormat
+	}
+
+	sb, err := hex.DecodeString(sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrBadSignatureFormat, err)
+	}
+
+	return hashFunc, sb, nil
+}
+
+// matchHmacs returns the hmac of which the digest returned by sum equals the
+// signature sb. If we have a match with any valid hmac, we can validate
+// successfully. All the hmacs are evaluated without short-circuit, so that
+// the timing reveals neither the number of hmacs nor which one matches.
+// The earlier level wins if the hmacs of several levels match.
+func matchHmacs(sb []byte, sets []levelHmacs, sum func(key []byte) []byte) (HmacMatch, error) {
+	matched, setIndex, index := 0, 0, 0
+	for i := range sets {
+		for j, key := range extractTokens(sets[i].secrets) {
+			eq := subtle.ConstantTimeCompare(sb, sum(key))
+			setIndex = subtle.ConstantTimeSelect(eq&^matched, i, setIndex)
+			index = subtle.ConstantTimeSelect(eq&^matched, j, index)
+			matched |= eq
+		}
+	}
+
+	if matched != 1 {
+		return HmacMatch{}, ErrSignatureMismatch
+	}
+
+	set := &sets[setIndex]
+
+	return HmacMatch{
+		Level:     set.level,
+		Index:     index,
+		CreatedAt: set.secrets[index].CreatedAt,
+	}, nil
+}
+
+// ValidateRequest reads the body of request and validates it with the signature
+// in X-Hub-Signature-256 or X-Hub-Signature header, or the headers configured
+// by WithHeaderNames. See ValidatePayloadFromRequest.
+//
+// The webhook can be delivered as application/json or application/x-www-form-urlencoded.
+// In the latter case, the signature is of the whole body, and the returned payload
+// is the json in the payload field of form.
+func (v *Validator) ValidateRequest(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, v.maxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errReadBody, err)
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if int64(len(body)) > v.maxPayloadSize {
+		v.metrics.Received(len(body))
+		v.metrics.Failed(FailureReasonTooLarge)
+
+		return nil, ErrPayloadTooLarge
+	}
+
+	payload := body
+	if isFormContent(r.Header.Get("Content-Type")) {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			v.metrics.Received(len(body))
+			v.metrics.Failed(FailureReasonInvalidPayload)
+
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+
+		payload = []byte(form.Get("payload"))
+	}
+
+	eventType := r.Header.Get(v.headers.Event)
+	if _, err := v.validateSigned(r.Context(), eventType, body, payload, v.signature(r)); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// isJSONContent tells whether the content type is application/json.
+func isJSONContent(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && t == "application/json"
+}
+
+// isFormContent tells whether the content type is application/x-www-form-urlencoded.
+func isFormContent(contentType string) bool {
+	t, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && t == "application/x-www-form-urlencoded"
+}
+
+// HeaderNames returns the names of headers of webhook request, see WithHeaderNames.
+func (v *Validator) HeaderNames() HeaderNames {
+	return v.headers
+}
+
+// signatureHeaders returns the names of signature headers for the messages.
+func (v *Validator) signatureHeaders() string {
+	return v.headers.Signature256 + " or " + v.headers.Signature
+}
+
+// signature returns the signature of request, the one of preferred algorithm takes precedence.
+func (v *Validator) signature(r *http.Request) string {
+	first, second := v.headers.Signature256, v.headers.Signature
+	if v.algorithm == AlgorithmSHA1 {
+		first, second = second, first
+	}
+
+	if sig := r.Header.Get(first); sig != "" {
+		return sig
+	}
+
+	return r.Header.Get(second)
+}
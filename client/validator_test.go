@@ -0,0 +1,205 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const multiTokenSecretFile = `
+"*":
+  - value: first
+  - value: second
+  - value: third
+`
+
+func TestValidateEventWithMultipleTokens(t *testing.T) {
+	payload := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+	gen := func() []byte { return []byte(multiTokenSecretFile) }
+
+	cases := []struct {
+		name    string
+		sig     string
+		index   int
+		wantErr error
+	}{
+		{name: "first matches", sig: PayloadSignature256(payload, []byte("first")), index: 0},
+		{name: "last matches", sig: PayloadSignature256(payload, []byte("third")), index: 2},
+		{name: "sha1 of last matches", sig: PayloadSignature(payload, []byte("third")), index: 2},
+		{name: "none matches", sig: PayloadSignature256(payload, []byte("fourth")), wantErr: ErrSignatureMismatch},
+	}
+
+	v := NewValidator(WithTokenGenerator(gen))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := v.ValidateEvent("", payload, c.sig)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("got error %v, want %v", err, c.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if m.Level != HmacLevelGlobal || m.Index != c.index {
+				t.Errorf("got match %+v, want index %d of %s", m, c.index, HmacLevelGlobal)
+			}
+		})
+	}
+}
+
+func TestDecodeSignature(t *testing.T) {
+	cases := []struct {
+		name    string
+		sig     string
+		wantErr bool
+	}{
+		{name: "sha256", sig: "sha256=0a0b"},
+		{name: "sha1", sig: "sha1=0a0b"},
+		{name: "unknown algorithm", sig: "md5=0a0b", wantErr: true},
+		{name: "no prefix", sig: "0a0b", wantErr: true},
+		{name: "empty digest", sig: "sha256=", wantErr: true},
+		{name: "odd length", sig: "sha256=0a0", wantErr: true},
+		{name: "not hex", sig: "sha256=zz", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := decodeSignature(c.sig)
+			if c.wantErr != errors.Is(err, ErrBadSignatureFormat) {
+				t.Errorf("got error %v, want bad format %t", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchHmacsPrefersEarlierLevel(t *testing.T) {
+	sets := []levelHmacs{
+		{level: HmacLevelRepo, secrets: hmacsForRepo{{Value: "a"}, {Value: "b"}}},
+		{level: HmacLevelGlobal, secrets: hmacsForRepo{{Value: "b"}}},
+	}
+
+	m, err := matchHmacs([]byte("b"), sets, func(key []byte) []byte { return key })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.Level != HmacLevelRepo || m.Index != 1 {
+		t.Errorf("got match %+v, want index 1 of %s", m, HmacLevelRepo)
+	}
+}
+
+func BenchmarkValidateEventLastTokenMatches(b *testing.B) {
+	payload := []byte(`{"repository":{"full_name":"owner/repo"}}`)
+	sig := PayloadSignature256(payload, []byte("third"))
+	v := NewValidator(WithTokenGenerator(func() []byte { return []byte(multiTokenSecretFile) }))
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := v.ValidateEvent("", payload, sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValidateRequestEncodings(t *testing.T) {
+	payload := `{"action":"opened","repository":{"full_name":"owner/repo"}}`
+	form := "payload=" + url.QueryEscape(payload)
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		signed      string
+		wantErr     bool
+	}{
+		{name: "json", contentType: "application/json", body: payload, signed: payload},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", body: payload, signed: payload},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: form, signed: form},
+		{name: "form signed over the payload", contentType: "application/x-www-form-urlencoded", body: form, signed: payload, wantErr: true},
+	}
+
+	v := NewValidator(WithTokenGenerator(func() []byte { return []byte("secret") }))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(c.body))
+			r.Header.Set("Content-Type", c.contentType)
+			r.Header.Set("X-GitHub-Event", "issues")
+			r.Header.Set("X-Hub-Signature-256", PayloadSignature256([]byte(c.signed), []byte("secret")))
+
+			got, err := v.ValidateRequest(r)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(got) != payload {
+				t.Errorf("got payload %s, want %s", got, payload)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookContentType(t *testing.T) {
+	payload := `{"zen":"z"}`
+
+	cases := []struct {
+		name        string
+		contentType string
+		wantStatus  int
+	}{
+		{name: "json", contentType: "application/json", wantStatus: http.StatusOK},
+		{name: "form", contentType: "application/x-www-form-urlencoded", wantStatus: http.StatusOK},
+		{name: "text", contentType: "text/plain", wantStatus: http.StatusBadRequest},
+		{name: "missing", wantStatus: http.StatusBadRequest},
+	}
+
+	v := NewValidator(WithTokenGenerator(func() []byte { return []byte("secret") }))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body := payload
+			if c.contentType == "application/x-www-form-urlencoded" {
+				body = "payload=" + url.QueryEscape(payload)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+			r.Header.Set("Content-Type", c.contentType)
+			r.Header.Set("X-GitHub-Event", "ping")
+			r.Header.Set("X-GitHub-Delivery", "guid")
+			r.Header.Set("X-Hub-Signature-256", PayloadSignature256([]byte(body), []byte("secret")))
+
+			w := httptest.NewRecorder()
+			_, _, got, ok, status := v.ValidateWebhook(w, r)
+
+			if c.wantStatus == http.StatusOK {
+				if !ok || string(got) != payload {
+					t.Errorf("got payload %s, ok %t, status %d", got, ok, status)
+				}
+
+				return
+			}
+
+			if ok || status != c.wantStatus {
+				t.Errorf("got ok %t, status %d, want status %d", ok, status, c.wantStatus)
+			}
+		})
+	}
+}
@@ -1,10 +1,23 @@
 package client
 
 import (
-	"io/ioutil"
+	"errors"
+	"fmt"
 	"net/http"
+)
+
+// MaxPayloadSize is the max size of the payload which will be read from a webhook request.
+var MaxPayloadSize int64 = 10 << 20
+
+var (
+	// ErrPayloadTooLarge is returned when the payload exceeds MaxPayloadSize.
+	ErrPayloadTooLarge = errors.New("payload too large")
+
+	// ErrParseEvent is returned when the validated payload can't be parsed
+	// to the event of its type.
+	ErrParseEvent = errors.New("failed to parse event")
 
-	"github.com/sirupsen/logrus"
+	errReadBody = errors.New("failed to read request body")
 )
 
 // ValidateWebhook ensures that the provided request conforms to the
@@ -16,6 +29,15 @@ func ValidateWebhook(
 	w http.ResponseWriter,
 	r *http.Request,
 	tokenGenerator func() []byte,
+) (eType string, guid string, payload []byte, ok bool, status int) {
+	return newDefaultValidator(tokenGenerator).ValidateWebhook(w, r)
+}
+
+// ValidateWebhook is the same as the function ValidateWebhook except that the
+// options of Validator are applied, such as the names of headers.
+func (v *Validator) ValidateWebhook(
+	w http.ResponseWriter,
+	r *http.Request,
 ) (eType string, guid string, payload []byte, ok bool, status int) {
 	defer r.Body.Close()
 	// Header checks: It must be a POST with an event type and a signature.
@@ -26,48 +48,54 @@ func ValidateWebhook(
 		return
 	}
 
-	if eType = r.Header.Get("X-GitHub-Event"); eType == "" {
+	if eType = r.Header.Get(v.headers.Event); eType == "" {
 		status = http.StatusBadRequest
-		responseHTTPError(w, status, "400 Bad Request: Missing X-GitHub-Event Header")
+		responseHTTPError(w, status, "400 Bad Request: Missing "+v.headers.Event+" Header")
 
 		return
 	}
 
-	if guid = r.Header.Get("X-GitHub-Delivery"); guid == "" {
+	if guid = r.Header.Get(v.headers.Delivery); guid == "" {
 		status = http.StatusBadRequest
-		responseHTTPError(w, status, "400 Bad Request: Missing X-GitHub-Delivery Header")
+		responseHTTPError(w, status, "400 Bad Request: Missing "+v.headers.Delivery+" Header")
 
 		return
 	}
 
-	sig := r.Header.Get("X-Hub-Signature")
-	if sig == "" {
+	if sig := v.signature(r); sig == "" {
 		status = http.StatusForbidden
-		responseHTTPError(w, status, "403 Forbidden: Missing X-Hub-Signature")
+		responseHTTPError(w, status, "403 Forbidden: Missing "+v.signatureHeaders()+" Header")
+
 		return
 	}
 
-	if contentType := r.Header.Get("content-type"); contentType != "application/json" {
+	if contentType := r.Header.Get("content-type"); !isJSONContent(contentType) && !isFormContent(contentType) {
 		status = http.StatusBadRequest
 		responseHTTPError(
 			w, status,
-			"400 Bad Request: Hook only accepts content-type: application/json - please reconfigure this hook on GitHub",
+			"400 Bad Request: Hook only accepts content-type: application/json or "+
+				"application/x-www-form-urlencoded - please reconfigure this hook on GitHub",
 		)
 
 		return
 	}
 
-	payload, err := ioutil.ReadAll(r.Body)
+	payload, err := v.ValidateRequest(r)
 	if err != nil {
-		status = http.StatusInternalServerError
-		responseHTTPError(w, status, "500 Internal Server Error: Failed to read request body")
-		return
-	}
+		switch {
+		case errors.Is(err, ErrPayloadTooLarge):
+			status = http.StatusRequestEntityTooLarge
+			responseHTTPError(w, status, "413 Request Entity Too Large: Payload is too large")
 
-	// Validate the payload with our HMAC secret.
-	if !ValidatePayload(payload, sig, tokenGenerator) {
-		status = http.StatusForbidden
-		responseHTTPError(w, status, "403 Forbidden: Invalid X-Hub-Signature")
+		case errors.Is(err, errReadBody):
+			status = http.StatusInternalServerError
+			responseHTTPError(w, status, "500 Internal Server Error: Failed to read request body")
+
+		default:
+			// Validate the payload with our HMAC secret.
+			status = http.StatusForbidden
+			responseHTTPError(w, status, "403 Forbidden: Invalid "+v.signatureHeaders())
+		}
 
 		return
 	}
@@ -78,13 +106,44 @@ func ValidateWebhook(
 	return
 }
 
+// ValidatePayloadFromRequest reads the body of request and validates it with
+// the signature in X-Hub-Signature-256 or X-Hub-Signature header. At most
+// MaxPayloadSize bytes will be read. The body of request is restored, so it
+// can be read again by the downstream handlers.
+func ValidatePayloadFromRequest(r *http.Request, tokenGenerator func() []byte) ([]byte, error) {
+	return newDefaultValidator(tokenGenerator).ValidateRequest(r)
+}
+
+// ValidateAndParse reads the body of request once, validates it and parses it to
+// the typed event of the type in X-GitHub-Event header. See ParseEvent. The error
+// is ErrParseEvent if the payload is valid but can't be parsed, otherwise it is
+// the error of validation.
+func ValidateAndParse(r *http.Request, tokenGenerator func() []byte) (eventType string, event interface{}, err error) {
+	return newDefaultValidator(tokenGenerator).ValidateAndParse(r)
+}
+
+// ValidateAndParse is the same as the function ValidateAndParse except that the
+// options of Validator are applied.
+func (v *Validator) ValidateAndParse(r *http.Request) (eventType string, event interface{}, err error) {
+	payload, err := v.ValidateRequest(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	eventType = r.Header.Get(v.headers.Event)
+
+	if event, err = ParseEvent(eventType, payload); err != nil {
+		return eventType, nil, fmt.Errorf("%w: %v", ErrParseEvent, err)
+	}
+
+	return eventType, event, nil
+}
+
 func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
-	logrus.WithFields(
-		logrus.Fields{
-			"response":    response,
-			"status-code": statusCode,
-		},
-	).Debug(response)
+	defaultLogger.Debug(response, LogFields{
+		"response":    response,
+		"status-code": statusCode,
+	})
 
 	http.Error(w, response, statusCode)
 }
@@ -0,0 +1,233 @@
+package framework
+
+import (
+	"net/http"
+
+	"github.com/google/go-github/v36/github"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/opensourceways/robot-github-lib/client"
+)
+
+// Dispatcher validates the webhook with hmac and invokes the handler registered
+// for the event type synchronously. The event which has no handler is ignored.
+type Dispatcher struct {
+	validator *client.Validator
+
+	handlers map[string]func(interface{}) error
+
+	deliveries client.DeliveryStore
+
+	allowedEvents    sets.String
+	ignoreDisallowed bool
+}
+
+// NewDispatcher returns a Dispatcher which validates the webhook with
+// the hmac secret returned by tokenGenerator. The options configure the
+// Validator, such as the names of headers, see client.WithHeaderNames.
+func NewDispatcher(tokenGenerator func() []byte, opts ...client.Option) *Dispatcher {
+	opts = append([]client.Option{client.WithTokenGenerator(tokenGenerator)}, opts...)
+
+	return &Dispatcher{
+		validator: client.NewValidator(opts...),
+		handlers:  map[string]func(interface{}) error{},
+	}
+}
+
+// UseDeliveryStore makes the dispatcher skip the deliveries seen recently.
+// The delivery which fails to be parsed or handled is forgotten, so that it
+// is processed again when github redelivers it.
+func (d *Dispatcher) UseDeliveryStore(s client.DeliveryStore) {
+	d.deliveries = s
+}
+
+// AllowedEvents makes the dispatcher reject the webhook of which the event type
+// is not one of eventTypes with 403, before validating and parsing it. The ping
+// event is always allowed. See IgnoreDisallowedEvents.
+func (d *Dispatcher) AllowedEvents(eventTypes ...string) {
+	d.allowedEvents = sets.NewString(eventTypes...).Insert(client.EventTypePing)
+}
+
+// IgnoreDisallowedEvents makes the dispatcher respond 200 to the webhook of the
+// event type which is not allowed instead of 403, so that github doesn't show
+// the failed deliveries. See AllowedEvents.
+func (d *Dispatcher) IgnoreDisallowedEvents() {
+	d.ignoreDisallowed = true
+}
+
+// OnIssues registers the handler of github.IssuesEvent.
+func (d *Dispatcher) OnIssues(fn func(*github.IssuesEvent) error) {
+	d.handlers["issues"] = func(e interface{}) error {
+		return fn(e.(*github.IssuesEvent))
+	}
+}
+
+// OnIssueComment registers the handler of github.IssueCommentEvent.
+func (d *Dispatcher) OnIssueComment(fn func(*github.IssueCommentEvent) error) {
+	d.handlers["issue_comment"] = func(e interface{}) error {
+		return fn(e.(*github.IssueCommentEvent))
+	}
+}
+
+// OnPullRequest registers the handler of github.PullRequestEvent.
+func (d *Dispatcher) OnPullRequest(fn func(*github.PullRequestEvent) error) {
+	d.handlers["pull_request"] = func(e interface{}) error {
+		return fn(e.(*github.PullRequestEvent))
+	}
+}
+
+// OnPullRequestReview registers the handler of github.PullRequestReviewEvent.
+func (d *Dispatcher) OnPullRequestReview(fn func(*github.PullRequestReviewEvent) error) {
+	d.handlers["pull_request_review"] = func(e interface{}) error {
+		return fn(e.(*github.PullRequestReviewEvent))
+	}
+}
+
+// OnPullRequestReviewComment registers the handler of github.PullRequestReviewCommentEvent.
+func (d *Dispatcher) OnPullRequestReviewComment(fn func(*github.PullRequestReviewCommentEvent) error) {
+	d.handlers["pull_request_review_comment"] = func(e interface{}) error {
+		return fn(e.(*github.PullRequestReviewCommentEvent))
+	}
+}
+
+// OnPush registers the handler of github.PushEvent.
+func (d *Dispatcher) OnPush(fn func(*github.PushEvent) error) {
+	d.handlers["push"] = func(e interface{}) error {
+		return fn(e.(*github.PushEvent))
+	}
+}
+
+// OnStatus registers the handler of github.StatusEvent.
+func (d *Dispatcher) OnStatus(fn func(*github.StatusEvent) error) {
+	d.handlers["status"] = func(e interface{}) error {
+		return fn(e.(*github.StatusEvent))
+	}
+}
+
+// OnCommitComment registers the handler of github.CommitCommentEvent.
+func (d *Dispatcher) OnCommitComment(fn func(*github.CommitCommentEvent) error) {
+	d.handlers["commit_comment"] = func(e interface{}) error {
+		return fn(e.(*github.CommitCommentEvent))
+	}
+}
+
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !d.isAllowed(w, r) {
+		return
+	}
+
+	eventType, eventGUID, payload, ok, _ := d.validator.ValidateWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	l := logrus.WithFields(
+		logrus.Fields{
+			"event-type": eventType,
+			"event_id":   eventGUID,
+		},
+	)
+
+	if d.deliveries != nil {
+		seen, err := d.deliveries.MarkSeen(eventGUID)
+		if err != nil {
+			l.WithError(err).Error("failed to check the delivery")
+		} else if seen {
+			l.Debug("Ignoring redelivered event")
+
+			return
+		}
+	}
+
+	if client.IsPing(eventType) {
+		d.handlePing(w, payload, l)
+
+		return
+	}
+
+	h, ok := d.handlers[eventType]
+	if !ok {
+		l.Debug("Ignoring unknown event type")
+
+		return
+	}
+
+	e, err := client.ParseEvent(eventType, payload)
+	if err != nil {
+		l.WithError(err).Error()
+		d.forget(eventGUID, l)
+		http.Error(w, "400 Bad Request: Failed to parse the event", http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h(e); err != nil {
+		l.WithError(err).Error()
+		d.forget(eventGUID, l)
+		http.Error(w, "500 Internal Server Error: Failed to handle the event", http.StatusInternalServerError)
+
+		return
+	}
+
+	l.Info()
+}
+
+// forget removes the delivery which fails to be processed from the delivery
+// store, so that the redelivery by github is not skipped. The delivery is
+// marked as seen before processing, so that the concurrent redeliveries are
+// not processed twice.
+func (d *Dispatcher) forget(eventGUID string, l *logrus.Entry) {
+	if d.deliveries == nil {
+		return
+	}
+
+	if err := d.deliveries.Forget(eventGUID); err != nil {
+		l.WithError(err).Error("failed to forget the delivery")
+	}
+}
+
+// isAllowed tells whether the event type of request is allowed, see AllowedEvents.
+// It responds the request if not.
+func (d *Dispatcher) isAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if d.allowedEvents == nil {
+		return true
+	}
+
+	eventType := r.Header.Get(d.validator.HeaderNames().Event)
+	if d.allowedEvents.Has(eventType) {
+		return true
+	}
+
+	logrus.WithField("event-type", eventType).Debug("Rejecting the event type which is not allowed")
+
+	if d.ignoreDisallowed {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		http.Error(w, "403 Forbidden: Event type is not allowed", http.StatusForbidden)
+	}
+
+	return false
+}
+
+// handlePing responds the ping event which github sends when the webhook is
+// created, so that operators can confirm the webhook and secret are correct.
+func (d *Dispatcher) handlePing(w http.ResponseWriter, payload []byte, l *logrus.Entry) {
+	e, err := client.ParseEvent(client.EventTypePing, payload)
+	if err != nil {
+		l.WithError(err).Error()
+		http.Error(w, "400 Bad Request: Failed to parse the event", http.StatusBadRequest)
+
+		return
+	}
+
+	ping := e.(*github.PingEvent)
+
+	l.WithFields(logrus.Fields{
+		"hook_id": ping.GetHookID(),
+		"zen":     ping.GetZen(),
+	}).Info("Received ping event")
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("pong"))
+}
@@ -0,0 +1,85 @@
+package framework
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+
+	"github.com/opensourceways/robot-github-lib/client"
+)
+
+func newWebhookRequest(eventType, guid, payload string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-GitHub-Event", eventType)
+	r.Header.Set("X-GitHub-Delivery", guid)
+	r.Header.Set("X-Hub-Signature-256", client.PayloadSignature256([]byte(payload), []byte("secret")))
+
+	return r
+}
+
+func TestDispatcherDeliveryStore(t *testing.T) {
+	payload := `{"action":"opened","repository":{"full_name":"owner/repo"}}`
+
+	cases := []struct {
+		name       string
+		failures   int
+		wantStatus []int
+		wantCalls  int
+	}{
+		{name: "redelivery is skipped", wantStatus: []int{200, 200}, wantCalls: 1},
+		{name: "failed delivery is processed again", failures: 1, wantStatus: []int{500, 200, 200}, wantCalls: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := NewDispatcher(func() []byte { return []byte("secret") })
+			d.UseDeliveryStore(client.NewMemoryDeliveryStore(0, 0))
+
+			calls := 0
+			d.OnIssues(func(*github.IssuesEvent) error {
+				if calls++; calls <= c.failures {
+					return errors.New("failed")
+				}
+
+				return nil
+			})
+
+			for i, want := range c.wantStatus {
+				w := httptest.NewRecorder()
+				d.ServeHTTP(w, newWebhookRequest("issues", "guid", payload))
+
+				if w.Code != want {
+					t.Errorf("delivery %d: got status %d, want %d", i, w.Code, want)
+				}
+			}
+
+			if calls != c.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, c.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDispatcherForgetsUnparsedDelivery(t *testing.T) {
+	d := NewDispatcher(func() []byte { return []byte("secret") })
+
+	store := client.NewMemoryDeliveryStore(0, 0)
+	d.UseDeliveryStore(store)
+	d.OnIssues(func(*github.IssuesEvent) error { return nil })
+
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, newWebhookRequest("issues", "guid", `{"action":1,"repository":{"full_name":"owner/repo"}}`))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if seen, _ := store.MarkSeen("guid"); seen {
+		t.Error("the delivery failed to be parsed is not forgotten")
+	}
+}